@@ -15,7 +15,12 @@
 // - Imports the greeting package from pkg/greeting
 // - Imports the logger package from pkg/logger for context-aware logging
 // - Sets up proper context handling with cancellation and timeout
+// - Optionally exposes the log level on an admin endpoint (see below)
+// - Optionally records tracing spans to a file (see below)
 // - Implements signal handling for graceful shutdown
+// - Writes log messages asynchronously and flushes them before exiting
+// - Registers its cleanup as exit hooks, which also run when logger.Fatal exits
+// - Optionally writes log messages to a rotated file (see below)
 // - Calls the Greet function with a name
 // - Handles different types of errors that might occur
 // - Prints the resulting greeting message to standard output
@@ -30,6 +35,33 @@
 //
 //	bazel run //cmd:main
 //
+// # Admin Endpoint
+//
+// When the ADMIN_ADDR environment variable is set, the application serves the
+// minimum level of logger.DefaultLogger over HTTP while it is running, so that
+// debug logging can be turned on without a restart:
+//
+//	ADMIN_ADDR=localhost:6060 go run cmd/main.go
+//	curl http://localhost:6060/loglevel                               # {"level":"INFO"}
+//	curl -X PUT -d '{"level":"debug"}' http://localhost:6060/loglevel # {"level":"DEBUG"}
+//
+// # Log File
+//
+// When the LOG_FILE environment variable is set, log messages are written to that
+// file instead of standard error. The file is rotated at 10 MiB, keeping five
+// compressed backups, and reopened on SIGHUP so that logrotate can rotate it too:
+//
+//	LOG_FILE=/var/log/bazel8_go.log go run cmd/main.go
+//
+// # Tracing
+//
+// When the TRACE_FILE environment variable is set, the application appends the
+// spans of the run (main.run and the greeting spans below it) to that file as
+// newline-delimited JSON, breaking down where the time went. A W3C traceparent
+// value in TRACEPARENT makes the run part of the caller's trace:
+//
+//	TRACE_FILE=traces.jsonl TRACEPARENT=00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01 go run cmd/main.go
+//
 // # Testing
 //
 // Using Go's standard tools:
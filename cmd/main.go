@@ -8,13 +8,19 @@
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/abitofhelp/bazel8_go/pkg/greeting"
 	"github.com/abitofhelp/bazel8_go/pkg/logger"
+	"github.com/abitofhelp/bazel8_go/pkg/tracing"
 )
 
 // Variables to allow mocking in tests.
@@ -22,10 +28,11 @@
 // by allowing test code to replace them with mock implementations.
 var (
 	// greetFunc is a function variable that wraps greeting.Greet.
-	// In production code, it calls the actual greeting.Greet function.
+	// In production code, it calls the actual greeting.Greet function,
+	// decorated with middleware that normalizes the name.
 	// In tests, it can be replaced with a mock implementation to avoid
 	// dependencies on the real greeting package.
-	greetFunc = greeting.Greet
+	greetFunc = greeting.Chain(greeting.Greet, greeting.NormalizeMiddleware())
 
 	// osExit is a function variable that wraps os.Exit.
 	// This allows tests to prevent the program from actually exiting
@@ -36,6 +43,39 @@
 	// It receives OS signals like SIGINT (Ctrl+C) and SIGTERM
 	// to enable graceful shutdown of the application.
 	signalChan = make(chan os.Signal, 1)
+
+	// reopenChan is the channel that receives SIGHUP, upon which the log file
+	// is reopened after an external tool such as logrotate has moved it.
+	reopenChan = make(chan os.Signal, 1)
+)
+
+// adminAddrEnv is the environment variable that holds the listen address of the
+// admin endpoint (e.g. "localhost:6060"). The endpoint is disabled when it is unset.
+// It serves the log level of logger.DefaultLogger at /loglevel, so that debug
+// logging can be turned on while the application is running:
+//
+//	curl -X PUT -d '{"level":"debug"}' http://localhost:6060/loglevel
+const adminAddrEnv = "ADMIN_ADDR"
+
+// Log file settings.
+const (
+	// logFileEnv is the environment variable that holds the path of a file to
+	// which log messages are written instead of standard error.
+	logFileEnv = "LOG_FILE"
+	// logFileMaxSize is the size at which the log file is rotated.
+	logFileMaxSize = 10 << 20
+	// logFileMaxBackups is the number of rotated log files that are kept.
+	logFileMaxBackups = 5
+)
+
+// Tracing environment variables.
+const (
+	// traceFileEnv holds the path of a file to which the spans of the run are
+	// appended as newline-delimited JSON. Tracing is disabled when it is unset.
+	traceFileEnv = "TRACE_FILE"
+	// traceparentEnv holds a W3C traceparent value, such as one passed down by a
+	// calling process, whose trace the run continues.
+	traceparentEnv = "TRACEPARENT"
 )
 
 // main initializes and runs the application.
@@ -54,11 +94,17 @@ func main() {
 // run contains the main logic of the application, extracted for testability.
 // This function:
 // 1. Sets up context with cancellation for proper resource management
-// 2. Configures signal handling to enable graceful shutdown
-// 3. Creates a timeout context to prevent hanging operations
-// 4. Calls the greeting function with a name and winning amount
-// 5. Handles different types of errors that might occur
-// 6. Prints the resulting greeting message to standard output
+// 2. Makes logging asynchronous, to LOG_FILE if it is set, flushing the log messages before returning or exiting
+// 3. Records the run in a span, exported to TRACE_FILE if it is set
+// 4. Configures signal handling to enable graceful shutdown
+// 5. Starts the admin endpoint if ADMIN_ADDR is set
+// 6. Creates a timeout context to prevent hanging operations
+// 7. Calls the greeting function with a name and winning amount
+// 8. Handles different types of errors that might occur
+// 9. Prints the resulting greeting message to standard output
+//
+// The cleanup of steps 2 and 3 is also registered as logger exit hooks, so that
+// it happens when a fatal message terminates the application.
 //
 // By extracting this logic from main(), we can unit test it without
 // actually running the application, which makes testing more reliable.
@@ -67,6 +113,42 @@ func run() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Write log messages to LOG_FILE, if set, instead of standard error
+	output := log.Writer()
+	if path := os.Getenv(logFileEnv); path != "" {
+		file, closeFile, err := openLogFile(path)
+		if err != nil {
+			logger.DefaultLogger.Error(ctx, "Failed to open log file: %v", err)
+		} else {
+			defer closeFile()
+			defer logger.RegisterExitHook(func(context.Context) { closeFile() })()
+			output = file
+		}
+	}
+
+	// Write log messages in the background, so that a slow sink does not hold up the run
+	stopAsyncLogging := startAsyncLogging(output)
+	defer stopAsyncLogging()
+	defer logger.RegisterExitHook(func(context.Context) { stopAsyncLogging() })()
+
+	// Record spans, if configured
+	if path := os.Getenv(traceFileEnv); path != "" {
+		exporter, err := tracing.NewJSONFileExporter(path)
+		if err != nil {
+			logger.DefaultLogger.Error(ctx, "Failed to enable tracing: %v", err)
+		} else {
+			defer enableTracing(exporter)()
+		}
+	}
+
+	// Continue the caller's trace, if any, and trace the run
+	if parent, err := logger.ParseTraceparent(os.Getenv(traceparentEnv)); err == nil {
+		ctx = logger.WithTraceContext(ctx, parent)
+	}
+	ctx, span := tracing.Start(ctx, "main.run")
+	defer span.End()
+	defer logger.RegisterExitHook(func(context.Context) { span.End() })()
+
 	// Set up signal handling
 	signal.Notify(signalChan, syscall.SIGINT, syscall.SIGTERM)
 
@@ -78,6 +160,17 @@ func run() {
 		cancel() // Cancel the context
 	}()
 
+	// Start the admin endpoint, if configured
+	if addr := os.Getenv(adminAddrEnv); addr != "" {
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			logger.DefaultLogger.Error(ctx, "Failed to start admin endpoint: %v", err)
+		} else {
+			logger.DefaultLogger.Info(ctx, "Admin endpoint listening on %s", ln.Addr())
+			defer serveAdmin(ln)()
+		}
+	}
+
 	// Create a context with timeout
 	timeoutCtx, timeoutCancel := context.WithTimeout(ctx, 5*time.Second)
 	defer timeoutCancel()
@@ -87,21 +180,128 @@ func run() {
 
 	// Handle different types of errors
 	if err != nil {
+		span.RecordError(err)
+
+		// End the span and flush the log messages first, as exiting skips the deferred calls
+		exit := func(code int) {
+			hookCtx, hookCancel := context.WithTimeout(context.Background(), logger.DefaultExitTimeout)
+			defer hookCancel()
+			_ = logger.RunExitHooks(hookCtx)
+			osExit(code)
+		}
+
 		if errors.Is(err, greeting.ErrInvalidName) {
 			logger.DefaultLogger.Error(ctx, "Invalid name provided: %v", err)
-			osExit(1)
+			exit(1)
 		} else if errors.Is(err, greeting.ErrContextCanceled) {
 			logger.DefaultLogger.Warning(ctx, "Operation was canceled: %v", err)
-			osExit(2)
+			exit(2)
 		} else if errors.Is(err, greeting.ErrContextDeadlineExceeded) {
 			logger.DefaultLogger.Warning(ctx, "Operation timed out: %v", err)
-			osExit(3)
+			exit(3)
 		} else {
 			logger.DefaultLogger.Error(ctx, "Unexpected error: %v", err)
-			osExit(4)
+			exit(4)
 		}
 	}
 
 	// Print the greeting message
 	fmt.Println(message)
 }
+
+// serveAdmin serves the admin endpoint on the listener in a separate goroutine.
+// It returns a function that shuts the endpoint down, waiting briefly for
+// in-flight requests to complete.
+func serveAdmin(ln net.Listener) (stop func()) {
+	mux := http.NewServeMux()
+	mux.Handle("/loglevel", logger.DefaultLogger.AtomicLevel())
+
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.DefaultLogger.Error(context.Background(), "Admin endpoint failed: %v", err)
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	}
+}
+
+// logFlushTimeout bounds how long shutdown waits for queued log messages to be written.
+const logFlushTimeout = time.Second
+
+// startAsyncLogging makes the standard logger, which backs logger.DefaultLogger,
+// write to w through a logger.AsyncWriter. It returns a function that restores
+// the previous output and waits up to logFlushTimeout for the queued messages to
+// be written. The function may be called more than once.
+func startAsyncLogging(w io.Writer) (stop func()) {
+	previous := log.Writer()
+	async := logger.NewAsyncWriter(w)
+	log.SetOutput(async)
+
+	return sync.OnceFunc(func() {
+		log.SetOutput(previous)
+
+		ctx, cancel := context.WithTimeout(context.Background(), logFlushTimeout)
+		defer cancel()
+		if err := async.Close(ctx); err != nil {
+			logger.DefaultLogger.Warning(ctx, "Failed to flush log messages: %v", err)
+		}
+		if dropped := async.Dropped(); dropped > 0 {
+			logger.DefaultLogger.Warning(ctx, "Dropped %d log messages", dropped)
+		}
+	})
+}
+
+// openLogFile opens the log file at path, rotating it by size, and reopens it
+// whenever SIGHUP is received, so that it can also be rotated by logrotate. It
+// returns a function that stops reopening and closes the file, which may be
+// called more than once.
+func openLogFile(path string) (file *logger.RotatingFile, closeFile func(), err error) {
+	file, err = logger.NewRotatingFile(path,
+		logger.WithMaxSize(logFileMaxSize),
+		logger.WithMaxBackups(logFileMaxBackups),
+		logger.WithCompression(),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signal.Notify(reopenChan, syscall.SIGHUP)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-reopenChan:
+				if err := file.Reopen(); err != nil {
+					logger.DefaultLogger.Error(context.Background(), "Failed to reopen log file: %v", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return file, sync.OnceFunc(func() {
+		signal.Stop(reopenChan)
+		close(done)
+		_ = file.Close()
+	}), nil
+}
+
+// enableTracing installs a tracer that exports spans to exporter as
+// tracing.DefaultTracer. It returns a function that restores the previous tracer
+// and closes the exporter.
+func enableTracing(exporter *tracing.JSONFileExporter) (disable func()) {
+	previous := tracing.DefaultTracer
+	tracing.DefaultTracer = tracing.NewTracer(exporter)
+	return func() {
+		tracing.DefaultTracer = previous
+		if err := exporter.Close(); err != nil {
+			logger.DefaultLogger.Error(context.Background(), "Failed to close trace file: %v", err)
+		}
+	}
+}
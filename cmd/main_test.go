@@ -6,12 +6,22 @@
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"io"
+	"log"
+	"net"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/abitofhelp/bazel8_go/pkg/greeting"
+	"github.com/abitofhelp/bazel8_go/pkg/logger"
+	"github.com/abitofhelp/bazel8_go/pkg/tracing"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -138,3 +148,279 @@ func TestContextCancellation(t *testing.T) {
 	// Check that the exit code is correct
 	assert.Equal(t, 2, exitCode) // Context canceled error
 }
+
+// TestServeAdmin tests that the admin endpoint reads and updates the log level
+func TestServeAdmin(t *testing.T) {
+	// Save the original logger and restore it after the test
+	originalLogger := logger.DefaultLogger
+	defer func() {
+		logger.DefaultLogger = originalLogger
+	}()
+	logger.DefaultLogger = logger.NewContextLogger(log.New(io.Discard, "", 0))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	stop := serveAdmin(ln)
+	defer stop()
+
+	url := "http://" + ln.Addr().String() + "/loglevel"
+
+	// Read the current level
+	resp, err := http.Get(url)
+	assert.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `{"level":"INFO"}`, string(body))
+
+	// Turn on debug logging
+	req, err := http.NewRequest(http.MethodPut, url, strings.NewReader(`{"level":"debug"}`))
+	assert.NoError(t, err)
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, logger.LevelDebug, logger.DefaultLogger.Level())
+}
+
+// TestRunWithAdminEndpoint tests that run starts and stops the admin endpoint
+func TestRunWithAdminEndpoint(t *testing.T) {
+	// Save original functions and restore them after the test
+	originalGreetFunc := greetFunc
+	originalOsExit := osExit
+	defer func() {
+		greetFunc = originalGreetFunc
+		osExit = originalOsExit
+	}()
+
+	var exitCode int
+	osExit = func(code int) {
+		exitCode = code
+	}
+	greetFunc = func(ctx context.Context, name string) (string, error) {
+		return "Hello, Mike!\n", nil
+	}
+
+	t.Setenv(adminAddrEnv, "127.0.0.1:0")
+	run()
+	assert.Equal(t, 0, exitCode)
+}
+
+// TestRunWithTracing tests that run exports its spans to TRACE_FILE and
+// continues the trace passed in TRACEPARENT
+func TestRunWithTracing(t *testing.T) {
+	// Save original functions and restore them after the test
+	originalGreetFunc := greetFunc
+	originalOsExit := osExit
+	defer func() {
+		greetFunc = originalGreetFunc
+		osExit = originalOsExit
+	}()
+
+	var exitCode int
+	osExit = func(code int) {
+		exitCode = code
+	}
+	greetFunc = func(ctx context.Context, name string) (string, error) {
+		_, span := tracing.Start(ctx, "greet")
+		defer span.End()
+		return "", greeting.ErrInvalidName
+	}
+
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	t.Setenv(traceFileEnv, path)
+	t.Setenv(traceparentEnv, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	run()
+	assert.Equal(t, 1, exitCode)
+	assert.False(t, tracing.DefaultTracer.Enabled(), "The default tracer should be restored")
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Len(t, lines, 2)
+
+	var greetSpan, runSpan map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &greetSpan))
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &runSpan))
+	assert.Equal(t, "greet", greetSpan["name"])
+	assert.Equal(t, "main.run", runSpan["name"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", runSpan["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", runSpan["parent_span_id"])
+	assert.Equal(t, runSpan["span_id"], greetSpan["parent_span_id"])
+	assert.Equal(t, "ERROR", runSpan["status"])
+}
+
+// TestRunWithInvalidTraceFile tests that run continues without tracing when
+// TRACE_FILE cannot be opened
+func TestRunWithInvalidTraceFile(t *testing.T) {
+	// Save original functions and restore them after the test
+	originalGreetFunc := greetFunc
+	originalOsExit := osExit
+	defer func() {
+		greetFunc = originalGreetFunc
+		osExit = originalOsExit
+	}()
+
+	var exitCode int
+	osExit = func(code int) {
+		exitCode = code
+	}
+	greetFunc = func(ctx context.Context, name string) (string, error) {
+		return "Hello, Mike!\n", nil
+	}
+
+	t.Setenv(traceFileEnv, filepath.Join(t.TempDir(), "missing", "traces.jsonl"))
+	run()
+	assert.Equal(t, 0, exitCode)
+	assert.False(t, tracing.DefaultTracer.Enabled())
+}
+
+// TestRunFlushesAsyncLogging tests that run writes the standard logger's messages
+// through an asynchronous writer, and flushes and restores it before exiting
+func TestRunFlushesAsyncLogging(t *testing.T) {
+	// Save original functions and restore them after the test
+	originalGreetFunc := greetFunc
+	originalOsExit := osExit
+	originalOutput := log.Writer()
+	defer func() {
+		greetFunc = originalGreetFunc
+		osExit = originalOsExit
+		log.SetOutput(originalOutput)
+	}()
+
+	var logOutput bytes.Buffer
+	log.SetOutput(&logOutput)
+
+	var asyncOutput bool
+	greetFunc = func(ctx context.Context, name string) (string, error) {
+		_, asyncOutput = log.Writer().(*logger.AsyncWriter)
+		return "", greeting.ErrInvalidName
+	}
+
+	// Capture the log output at the time of exiting
+	var exitCode int
+	var outputAtExit string
+	osExit = func(code int) {
+		exitCode = code
+		outputAtExit = logOutput.String()
+	}
+
+	run()
+
+	assert.True(t, asyncOutput, "The standard logger should write asynchronously during the run")
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, outputAtExit, "ERROR: Invalid name provided", "Log messages should be flushed before exiting")
+	assert.Same(t, &logOutput, log.Writer(), "The original output should be restored")
+}
+
+// TestRunFlushesOnFatal tests that a fatal message logged during the run flushes
+// the log messages through the exit hooks that run registers
+func TestRunFlushesOnFatal(t *testing.T) {
+	// Save original functions and restore them after the test
+	originalGreetFunc := greetFunc
+	originalOutput := log.Writer()
+	defer func() {
+		greetFunc = originalGreetFunc
+		log.SetOutput(originalOutput)
+	}()
+
+	var logOutput bytes.Buffer
+	log.SetOutput(&logOutput)
+
+	// Capture the log output at the time of exiting
+	var exitCode int
+	var outputAtExit string
+	fatalLogger := logger.NewContextLogger(nil, logger.WithExitFunc(func(code int) {
+		exitCode = code
+		outputAtExit = logOutput.String()
+	}))
+	greetFunc = func(ctx context.Context, name string) (string, error) {
+		fatalLogger.Fatal(ctx, "Greeting backend unavailable")
+		return "Hello, Mike!", nil
+	}
+
+	run()
+
+	assert.Equal(t, 1, exitCode)
+	assert.Contains(t, outputAtExit, "FATAL: ", "Log messages should be flushed before exiting")
+	assert.Contains(t, outputAtExit, "Greeting backend unavailable")
+	assert.Same(t, &logOutput, log.Writer(), "The original output should be restored")
+}
+
+// TestRunWithLogFile tests that run writes log messages to LOG_FILE and reopens
+// it on SIGHUP
+func TestRunWithLogFile(t *testing.T) {
+	// Save original functions and restore them after the test
+	originalGreetFunc := greetFunc
+	originalOsExit := osExit
+	defer func() {
+		greetFunc = originalGreetFunc
+		osExit = originalOsExit
+	}()
+
+	var exitCode int
+	osExit = func(code int) {
+		exitCode = code
+	}
+
+	path := filepath.Join(t.TempDir(), "app.log")
+	greetFunc = func(ctx context.Context, name string) (string, error) {
+		logger.DefaultLogger.Info(ctx, "Before rotation")
+		assert.NoError(t, log.Writer().(*logger.AsyncWriter).Flush(ctx))
+
+		// Simulate logrotate moving the file and sending SIGHUP
+		assert.NoError(t, os.Rename(path, path+".moved"))
+		reopenChan <- syscall.SIGHUP
+		assert.Eventually(t, func() bool {
+			_, err := os.Stat(path)
+			return err == nil
+		}, time.Second, time.Millisecond)
+
+		logger.DefaultLogger.Info(ctx, "After rotation")
+		return "", greeting.ErrInvalidName
+	}
+
+	t.Setenv(logFileEnv, path)
+	run()
+	assert.Equal(t, 1, exitCode)
+
+	moved, err := os.ReadFile(path + ".moved")
+	assert.NoError(t, err)
+	assert.Contains(t, string(moved), "INFO: Before rotation")
+	assert.NotContains(t, string(moved), "After rotation")
+
+	current, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(current), "INFO: After rotation")
+	assert.Contains(t, string(current), "ERROR: Invalid name provided")
+}
+
+// TestRunWithInvalidLogFile tests that run logs to standard error when LOG_FILE
+// cannot be opened
+func TestRunWithInvalidLogFile(t *testing.T) {
+	// Save original functions and restore them after the test
+	originalGreetFunc := greetFunc
+	originalOsExit := osExit
+	originalOutput := log.Writer()
+	defer func() {
+		greetFunc = originalGreetFunc
+		osExit = originalOsExit
+		log.SetOutput(originalOutput)
+	}()
+
+	var logOutput bytes.Buffer
+	log.SetOutput(&logOutput)
+
+	var exitCode int
+	osExit = func(code int) {
+		exitCode = code
+	}
+	greetFunc = func(ctx context.Context, name string) (string, error) {
+		return "Hello, Mike!\n", nil
+	}
+
+	t.Setenv(logFileEnv, filepath.Join(t.TempDir(), "missing", "app.log"))
+	run()
+	assert.Equal(t, 0, exitCode)
+	assert.Contains(t, logOutput.String(), "ERROR: Failed to open log file")
+}
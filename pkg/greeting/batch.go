@@ -0,0 +1,125 @@
+package greeting
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultBatchConcurrency is the number of workers used by GreetBatch when
+// BatchOptions.Concurrency is not set.
+const DefaultBatchConcurrency = 8
+
+// Result is the outcome of greeting a single name in a batch or stream.
+type Result struct {
+	// Index is the position of the name in the input.
+	Index int
+	// Name is the name that was greeted.
+	Name string
+	// Message is the greeting message; it is empty if Err is set.
+	Message string
+	// Err is the error returned for this name, if any.
+	Err error
+}
+
+// BatchOptions configures GreetBatch.
+type BatchOptions struct {
+	// Concurrency is the maximum number of names greeted at the same time.
+	// Zero or negative values mean DefaultBatchConcurrency.
+	Concurrency int
+	// Greeter generates the individual greetings. Nil means DefaultGreeter.
+	Greeter Greeter
+}
+
+// GreetBatch greets many names using a bounded pool of workers.
+//
+// # Function Purpose
+//
+// This function fans the names out to at most opts.Concurrency workers and
+// collects one Result per name. Results are returned in input order regardless
+// of the order in which the greetings complete. A failure for one name does not
+// affect the others; it is reported in that name's Result.
+//
+// # Parameters
+//
+//   - ctx: A context.Context that can be used to cancel the whole batch or set a deadline.
+//
+//   - names: The names to greet.
+//
+//   - opts: The batch configuration. The zero value uses DefaultGreeter and
+//     DefaultBatchConcurrency workers.
+//
+// # Return Values
+//
+//   - []Result: One result per name, in input order. Names that were not greeted
+//     because the context ended carry ErrContextCanceled or ErrContextDeadlineExceeded.
+//
+// - error: ErrContextCanceled or ErrContextDeadlineExceeded if the context ended
+// before the batch completed, nil otherwise. Per-name errors such as
+// ErrInvalidName are only reported in the results.
+//
+// # Example Usage
+//
+//	results, err := greeting.GreetBatch(ctx, []string{"John", "", "Alice"}, greeting.BatchOptions{Concurrency: 4})
+//	if err != nil {
+//	    log.Printf("Batch interrupted: %v", err)
+//	}
+//	for _, r := range results {
+//	    if r.Err != nil {
+//	        log.Printf("Failed to greet %q: %v", r.Name, r.Err)
+//	        continue
+//	    }
+//	    fmt.Print(r.Message)
+//	}
+func GreetBatch(ctx context.Context, names []string, opts BatchOptions) ([]Result, error) {
+	greeter := opts.Greeter
+	if greeter == nil {
+		greeter = DefaultGreeter
+	}
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultBatchConcurrency
+	}
+	workers = min(workers, len(names))
+
+	results := make([]Result, len(names))
+	for i, name := range names {
+		results[i] = Result{Index: i, Name: name}
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].Message, results[i].Err = greeter.Greet(ctx, names[i])
+			}
+		}()
+	}
+
+	// Feed the workers until every name is dispatched or the context ends.
+	next := 0
+feed:
+	for ; next < len(names); next++ {
+		select {
+		case jobs <- next:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if next < len(names) {
+		err := mapContextError("GreetBatch", ctx.Err())
+		for i := next; i < len(names); i++ {
+			results[i].Err = err
+		}
+		return results, err
+	}
+	if ctx.Err() != nil {
+		return results, mapContextError("GreetBatch", ctx.Err())
+	}
+	return results, nil
+}
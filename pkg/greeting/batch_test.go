@@ -0,0 +1,104 @@
+package greeting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGreetBatch(t *testing.T) {
+	g, err := NewGreeter(WithProcessingDelay(0))
+	assert.NoError(t, err)
+
+	names := []string{"John", "", "Alice", "Bob"}
+	results, err := GreetBatch(context.Background(), names, BatchOptions{Concurrency: 2, Greeter: g})
+	assert.NoError(t, err)
+
+	if assert.Len(t, results, len(names)) {
+		for i, r := range results {
+			assert.Equal(t, i, r.Index)
+			assert.Equal(t, names[i], r.Name)
+		}
+		assert.Equal(t, "Howdy John!\n", results[0].Message)
+		assert.True(t, errors.Is(results[1].Err, ErrInvalidName), "Per-item errors should be reported")
+		assert.Equal(t, "Howdy Alice!\n", results[2].Message)
+		assert.Equal(t, "Howdy Bob!\n", results[3].Message)
+	}
+
+	results, err = GreetBatch(context.Background(), nil, BatchOptions{})
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestGreetBatchPreservesOrder(t *testing.T) {
+	// Later names finish first.
+	g := GreeterFunc(func(ctx context.Context, name string) (string, error) {
+		var n int
+		fmt.Sscanf(name, "%d", &n)
+		time.Sleep(time.Duration(10-n) * time.Millisecond)
+		return name, nil
+	})
+
+	names := []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
+	results, err := GreetBatch(context.Background(), names, BatchOptions{Concurrency: 10, Greeter: g})
+	assert.NoError(t, err)
+	for i, r := range results {
+		assert.Equal(t, names[i], r.Message)
+	}
+}
+
+func TestGreetBatchBoundsConcurrency(t *testing.T) {
+	var inFlight, peak atomic.Int32
+	g := GreeterFunc(func(ctx context.Context, name string) (string, error) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return name, nil
+	})
+
+	names := make([]string, 50)
+	for i := range names {
+		names[i] = fmt.Sprint(i)
+	}
+
+	_, err := GreetBatch(context.Background(), names, BatchOptions{Concurrency: 3, Greeter: g})
+	assert.NoError(t, err)
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+}
+
+func TestGreetBatchCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+	g := GreeterFunc(func(ctx context.Context, name string) (string, error) {
+		if calls.Add(1) == 2 {
+			cancel()
+		}
+		return name, nil
+	})
+
+	names := make([]string, 100)
+	for i := range names {
+		names[i] = fmt.Sprint(i)
+	}
+
+	results, err := GreetBatch(ctx, names, BatchOptions{Concurrency: 1, Greeter: g})
+	assert.True(t, errors.Is(err, ErrContextCanceled), "Expected ErrContextCanceled, got %v", err)
+	assert.Less(t, int(calls.Load()), len(names), "The batch should stop early")
+	assert.True(t, errors.Is(results[len(results)-1].Err, ErrContextCanceled), "Skipped names should carry the context error")
+
+	deadline, cancelDeadline := context.WithTimeout(context.Background(), -time.Second)
+	defer cancelDeadline()
+	_, err = GreetBatch(deadline, names, BatchOptions{Greeter: g})
+	assert.True(t, errors.Is(err, ErrContextDeadlineExceeded), "Expected ErrContextDeadlineExceeded, got %v", err)
+}
@@ -0,0 +1,239 @@
+package greeting
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/abitofhelp/bazel8_go/pkg/greeting/msgfmt"
+)
+
+// Message keys used by the greeting functions.
+const (
+	// MessageGreeting is the key of the plain greeting message. It receives the
+	// {name} argument.
+	MessageGreeting = "greeting"
+	// MessageGreetingWinnings is the key of the greeting that announces winnings.
+	// It receives the {name} and {amount} arguments.
+	MessageGreetingWinnings = "greeting.winnings"
+)
+
+// DefaultLocale is the locale used when no better match is available.
+const DefaultLocale Tag = "en"
+
+//go:embed locales/*.json
+var embeddedLocales embed.FS
+
+// Catalog holds per-locale message bundles and resolves messages using BCP 47
+// fallback chains.
+//
+// # Features
+//
+// - Bundles can be added programmatically or loaded from any fs.FS
+// - Messages are resolved per key along the fallback chain (e.g. pt-BR → pt → en)
+// - Templates use ICU MessageFormat syntax, including plural and select arguments
+// - Templates are validated when a bundle is added, not when they are rendered
+// - Thread-safe for concurrent use by multiple goroutines
+//
+// # Usage
+//
+// Most applications use DefaultCatalog, which contains the bundles embedded in
+// this package. Use NewCatalog, LoadCatalog or LoadCatalogDir to ship your own
+// translations.
+type Catalog struct {
+	mu            sync.RWMutex
+	defaultLocale Tag
+	bundles       map[Tag]map[string]*msgfmt.Message
+}
+
+// NewCatalog creates an empty catalog that falls back to the given default locale.
+// If the default locale is not a valid BCP 47 tag, the returned error wraps
+// ErrInvalidLocale.
+func NewCatalog(defaultLocale string) (*Catalog, error) {
+	tag, err := ParseTag(defaultLocale)
+	if err != nil {
+		return nil, err
+	}
+	return &Catalog{
+		defaultLocale: tag,
+		bundles:       make(map[Tag]map[string]*msgfmt.Message),
+	}, nil
+}
+
+// LoadCatalog creates a catalog from the message bundles found in the root of fsys.
+//
+// Each bundle is a JSON object mapping message keys to templates, stored in a
+// file named after its locale, for example "pt-BR.json". Files without the
+// ".json" extension are ignored.
+//
+// # Example
+//
+//	//go:embed translations/*.json
+//	var translations embed.FS
+//
+//	sub, _ := fs.Sub(translations, "translations")
+//	catalog, err := greeting.LoadCatalog(sub, "en")
+func LoadCatalog(fsys fs.FS, defaultLocale string) (*Catalog, error) {
+	c, err := NewCatalog(defaultLocale)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("reading message bundles: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".json" {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("reading message bundle %s: %w", entry.Name(), err)
+		}
+
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			return nil, fmt.Errorf("parsing message bundle %s: %w", entry.Name(), err)
+		}
+
+		if err := c.AddBundle(strings.TrimSuffix(entry.Name(), ".json"), messages); err != nil {
+			return nil, fmt.Errorf("loading message bundle %s: %w", entry.Name(), err)
+		}
+	}
+
+	return c, nil
+}
+
+// LoadCatalogDir creates a catalog from the message bundles stored in a directory.
+// It is a convenience wrapper around LoadCatalog and os.DirFS.
+func LoadCatalogDir(dir string, defaultLocale string) (*Catalog, error) {
+	return LoadCatalog(os.DirFS(dir), defaultLocale)
+}
+
+// AddBundle adds messages for a locale to the catalog. Messages already present
+// for the locale are replaced key by key.
+//
+// Each message is compiled as an ICU MessageFormat pattern using the plural rules
+// of the locale's language. All templates are validated before any of them is
+// added, so a failed call leaves the catalog unchanged. Invalid templates yield an
+// error wrapping a *msgfmt.SyntaxError that identifies the locale, key and position.
+func (c *Catalog) AddBundle(locale string, messages map[string]string) error {
+	tag, err := ParseTag(locale)
+	if err != nil {
+		return err
+	}
+
+	compiled := make(map[string]*msgfmt.Message, len(messages))
+	for key, message := range messages {
+		tmpl, err := msgfmt.Compile(string(tag), message)
+		if err != nil {
+			return fmt.Errorf("locale %s: message %q: %w", tag, key, err)
+		}
+		compiled[key] = tmpl
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	bundle, ok := c.bundles[tag]
+	if !ok {
+		bundle = make(map[string]*msgfmt.Message, len(compiled))
+		c.bundles[tag] = bundle
+	}
+	for key, tmpl := range compiled {
+		bundle[key] = tmpl
+	}
+	return nil
+}
+
+// DefaultLocale returns the locale the catalog falls back to.
+func (c *Catalog) DefaultLocale() Tag {
+	return c.defaultLocale
+}
+
+// Locales returns the locales that have a bundle in the catalog, sorted alphabetically.
+func (c *Catalog) Locales() []Tag {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	locales := make([]Tag, 0, len(c.bundles))
+	for tag := range c.bundles {
+		locales = append(locales, tag)
+	}
+	sort.Slice(locales, func(i, j int) bool { return locales[i] < locales[j] })
+	return locales
+}
+
+// Match returns the first locale in the fallback chain of the requested tag that
+// has a bundle in the catalog. If none does, the default locale is returned.
+func (c *Catalog) Match(requested Tag) Tag {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, tag := range FallbackChain(requested, c.defaultLocale) {
+		if _, ok := c.bundles[tag]; ok {
+			return tag
+		}
+	}
+	return c.defaultLocale
+}
+
+// Format renders the message identified by key for the requested locale.
+//
+// The message is looked up along the fallback chain of the requested locale, so
+// a key missing from "pt-BR" is taken from "pt" and then from the default locale.
+// If no bundle in the chain contains the key, the returned error wraps
+// ErrMessageNotFound.
+//
+// # Example
+//
+//	message, err := catalog.Format("pt-BR", greeting.MessageGreeting, map[string]any{"name": "João"})
+//	// message: "Olá João!"
+func (c *Catalog) Format(locale Tag, key string, args map[string]any) (string, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, tag := range FallbackChain(locale, c.defaultLocale) {
+		if tmpl, ok := c.bundles[tag][key]; ok {
+			return tmpl.Format(args)
+		}
+	}
+	return "", fmt.Errorf("%w: %q for locale %s", ErrMessageNotFound, key, locale)
+}
+
+// FormatContext renders the message identified by key for the locale stored in
+// the context by WithLocale. If the context carries no valid locale, the
+// catalog's default locale is used.
+func (c *Catalog) FormatContext(ctx context.Context, key string, args map[string]any) (string, error) {
+	locale, _ := LocaleFromContext(ctx)
+	return c.Format(locale, key, args)
+}
+
+// DefaultCatalog is the catalog used by the package-level greeting functions.
+// It contains the message bundles embedded in this package, falling back to
+// English. Replace it to ship additional or different translations.
+var DefaultCatalog = mustLoadEmbeddedCatalog()
+
+// mustLoadEmbeddedCatalog loads the message bundles embedded in this package.
+// The bundles are part of the source tree, so failing to load them is a programming error.
+func mustLoadEmbeddedCatalog() *Catalog {
+	sub, err := fs.Sub(embeddedLocales, "locales")
+	if err != nil {
+		panic(fmt.Sprintf("greeting: embedded locales: %v", err))
+	}
+	c, err := LoadCatalog(sub, string(DefaultLocale))
+	if err != nil {
+		panic(fmt.Sprintf("greeting: embedded locales: %v", err))
+	}
+	return c
+}
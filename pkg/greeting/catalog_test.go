@@ -0,0 +1,132 @@
+package greeting
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/abitofhelp/bazel8_go/pkg/greeting/msgfmt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogFormat(t *testing.T) {
+	c, err := NewCatalog("en")
+	assert.NoError(t, err)
+	assert.NoError(t, c.AddBundle("en", map[string]string{"greeting": "Howdy {name}!", "farewell": "Bye {name}!"}))
+	assert.NoError(t, c.AddBundle("pt", map[string]string{"greeting": "Olá {name}!"}))
+
+	args := map[string]any{"name": "João"}
+
+	message, err := c.Format("pt-BR", "greeting", args)
+	assert.NoError(t, err)
+	assert.Equal(t, "Olá João!", message, "pt-BR should fall back to pt")
+
+	message, err = c.Format("pt-BR", "farewell", args)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bye João!", message, "Keys missing from pt should fall back to en")
+
+	_, err = c.Format("pt", "missing", args)
+	assert.True(t, errors.Is(err, ErrMessageNotFound), "Expected ErrMessageNotFound, got %v", err)
+
+	_, err = c.Format("en", "greeting", nil)
+	assert.Error(t, err, "Missing arguments should be reported")
+
+	assert.Equal(t, Tag("pt"), c.Match("pt-BR"))
+	assert.Equal(t, Tag("en"), c.Match("ja"))
+	assert.Equal(t, []Tag{"en", "pt"}, c.Locales())
+}
+
+func TestCatalogAddBundleValidation(t *testing.T) {
+	c, err := NewCatalog("en")
+	assert.NoError(t, err)
+
+	assert.Error(t, c.AddBundle("en", map[string]string{"greeting": "Howdy {name!"}))
+	assert.Error(t, c.AddBundle("en", map[string]string{"greeting": "Howdy name}!"}))
+	assert.Error(t, c.AddBundle("en", map[string]string{"greeting": "Howdy {}!"}))
+	assert.True(t, errors.Is(c.AddBundle("e!", map[string]string{}), ErrInvalidLocale))
+	assert.Empty(t, c.Locales(), "Failed bundles should not be added")
+
+	_, err = NewCatalog("")
+	assert.True(t, errors.Is(err, ErrInvalidLocale))
+}
+
+func TestCatalogPluralMessages(t *testing.T) {
+	c, err := NewCatalog("en")
+	assert.NoError(t, err)
+	assert.NoError(t, c.AddBundle("en", map[string]string{"prizes": "{count, plural, one {# prize} other {# prizes}}"}))
+	assert.NoError(t, c.AddBundle("fr", map[string]string{"prizes": "{count, plural, one {# prix} other {# prix}}"}))
+	assert.NoError(t, c.AddBundle("ru", map[string]string{"prizes": "{count, plural, one {# приз} few {# приза} other {# призов}}"}))
+
+	message, err := c.Format("en", "prizes", map[string]any{"count": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "1 prize", message)
+
+	message, err = c.Format("ru-RU", "prizes", map[string]any{"count": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, "3 приза", message, "Plural rules should follow the bundle's language")
+
+	err = c.AddBundle("en", map[string]string{"broken": "{count, plural, one {# prize}}"})
+	var syntaxErr *msgfmt.SyntaxError
+	if assert.True(t, errors.As(err, &syntaxErr), "Expected *msgfmt.SyntaxError, got %v", err) {
+		assert.Equal(t, 30, syntaxErr.Column)
+	}
+	assert.Contains(t, err.Error(), `locale en: message "broken"`)
+}
+
+func TestLoadCatalog(t *testing.T) {
+	fsys := fstest.MapFS{
+		"en.json":    {Data: []byte(`{"greeting": "Howdy {name}!"}`)},
+		"pt-BR.json": {Data: []byte(`{"greeting": "Oi {name}!"}`)},
+		"README.md":  {Data: []byte(`ignored`)},
+	}
+
+	c, err := LoadCatalog(fsys, "en")
+	assert.NoError(t, err)
+	assert.Equal(t, []Tag{"en", "pt-BR"}, c.Locales())
+
+	_, err = LoadCatalog(fstest.MapFS{"en.json": {Data: []byte(`{`)}}, "en")
+	assert.Error(t, err, "Malformed JSON should be reported")
+
+	_, err = LoadCatalog(fstest.MapFS{"en.json": {Data: []byte(`{"greeting": "{"}`)}}, "en")
+	assert.Error(t, err, "Invalid templates should be reported")
+}
+
+func TestLoadCatalogDir(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "fr.json"), []byte(`{"greeting": "Salut {name} !"}`), 0o600))
+
+	c, err := LoadCatalogDir(dir, "fr")
+	assert.NoError(t, err)
+
+	message, err := c.FormatContext(context.Background(), "greeting", map[string]any{"name": "Marie"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Salut Marie !", message)
+}
+
+func TestDefaultCatalogLocalizedGreet(t *testing.T) {
+	tests := []struct {
+		locale   string
+		expected string
+	}{
+		{"en", "Howdy John!\n"},
+		{"en-US", "Howdy John!\n"},
+		{"pt-BR", "Olá John!\n"},
+		{"es", "¡Hola John!\n"},
+		{"ja", "Johnさん、こんにちは！\n"},
+		{"xx", "Howdy John!\n"},
+	}
+
+	g, err := NewGreeter(WithProcessingDelay(0))
+	assert.NoError(t, err)
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			message, err := g.Greet(WithLocale(context.Background(), tt.locale), "John")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, message)
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package greeting
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock abstracts the passage of time so that greeters can be tested without
+// sleeping. The methods mirror the functions of the time package.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on
+	// the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the Clock backed by the time package. It is the default clock
+// of every greeter.
+var SystemClock Clock = systemClock{}
+
+// systemClock implements Clock using the time package.
+type systemClock struct{}
+
+// Now returns time.Now().
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// After returns time.After(d).
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// ManualClock is a Clock whose time only moves when Advance or Set is called.
+// It lets tests drive time deterministically, for example to verify that a
+// greeter honors cancellation while it is waiting, without sleeping.
+//
+// # Example
+//
+//	clock := greeting.NewManualClock(time.Now())
+//	g, _ := greeting.NewGreeter(greeting.WithClock(clock))
+//
+//	go func() {
+//	    clock.BlockUntil(1)              // Wait until the greeter is processing
+//	    clock.Advance(greeting.DefaultProcessingDelay)
+//	}()
+//	message, err := g.Greet(ctx, "John")
+//
+// A ManualClock is safe for concurrent use by multiple goroutines.
+type ManualClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []manualWaiter
+}
+
+// manualWaiter is a pending After call.
+type manualWaiter struct {
+	deadline time.Time
+	ch       chan time.Time
+}
+
+// NewManualClock creates a ManualClock set to the given time.
+func NewManualClock(now time.Time) *ManualClock {
+	c := &ManualClock{now: now}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now returns the clock's current time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the clock's time once it has been
+// advanced by at least d. Non-positive durations fire immediately.
+func (c *ManualClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, manualWaiter{deadline: c.now.Add(d), ch: ch})
+	c.cond.Broadcast()
+	return ch
+}
+
+// Advance moves the clock forward by d and fires every After channel whose
+// deadline has been reached.
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(c.now.Add(d))
+}
+
+// Set moves the clock to t and fires every After channel whose deadline has
+// been reached. Moving the clock backwards fires nothing.
+func (c *ManualClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setLocked(t)
+}
+
+// setLocked updates the time and fires due waiters. The caller must hold c.mu.
+func (c *ManualClock) setLocked(t time.Time) {
+	c.now = t
+
+	pending := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.deadline.After(t) {
+			pending = append(pending, w)
+			continue
+		}
+		w.ch <- t
+	}
+	c.waiters = pending
+	c.cond.Broadcast()
+}
+
+// Waiters returns the number of After channels that have not fired yet,
+// including channels whose receiver has stopped waiting.
+func (c *ManualClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil blocks until at least n After channels are pending. Tests use it
+// to wait until the code under test is waiting on the clock before advancing it.
+func (c *ManualClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
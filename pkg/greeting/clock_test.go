@@ -0,0 +1,56 @@
+package greeting
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemClock(t *testing.T) {
+	before := time.Now()
+	assert.False(t, SystemClock.Now().Before(before))
+
+	select {
+	case <-SystemClock.After(time.Millisecond):
+	case <-time.After(time.Second):
+		t.Fatal("SystemClock.After did not fire")
+	}
+}
+
+func TestManualClock(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := NewManualClock(start)
+	assert.Equal(t, start, clock.Now())
+
+	immediate := clock.After(0)
+	assert.Equal(t, start, <-immediate, "Non-positive durations should fire immediately")
+
+	short := clock.After(time.Second)
+	long := clock.After(time.Minute)
+	assert.Equal(t, 2, clock.Waiters())
+
+	clock.Advance(500 * time.Millisecond)
+	assert.Len(t, short, 0, "Channels should not fire before their deadline")
+
+	clock.Advance(500 * time.Millisecond)
+	assert.Equal(t, start.Add(time.Second), <-short)
+	assert.Equal(t, 1, clock.Waiters())
+
+	clock.Set(start.Add(time.Hour))
+	assert.Equal(t, start.Add(time.Hour), <-long)
+	assert.Equal(t, 0, clock.Waiters())
+}
+
+func TestManualClockBlockUntil(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+
+	fired := make(chan time.Time)
+	go func() {
+		fired <- <-clock.After(time.Second)
+	}()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	assert.Equal(t, time.Unix(1, 0), <-fired)
+}
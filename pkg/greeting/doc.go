@@ -14,14 +14,23 @@
 // - Formatting of monetary amounts in a human-readable way
 // - Context-aware operations with support for cancellation and timeouts
 // - Comprehensive error handling with specific error types
-// - Logging of operations for debugging and monitoring
+// - Logging of operations for debugging and monitoring, with names redacted
 //
 // # Basic Usage
 //
 // Here's a simple example of how to use the greeting package:
 //
 //	ctx := context.Background()
-//	message, err := greeting.Greet(ctx, "John", 1234567)
+//	message, err := greeting.Greet(ctx, "John")
+//	if err != nil {
+//	    log.Fatalf("Error generating greeting: %v", err)
+//	}
+//	fmt.Println(message)
+//	// Output: Howdy John!
+//
+// To announce winnings as part of the greeting, use GreetWithWinnings:
+//
+//	message, err := greeting.GreetWithWinnings(ctx, "John", 1234567)
 //	if err != nil {
 //	    log.Fatalf("Error generating greeting: %v", err)
 //	}
@@ -34,7 +43,7 @@
 //
 //	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 //	defer cancel() // Always call cancel to release resources
-//	message, err := greeting.Greet(ctx, "John", 1234567)
+//	message, err := greeting.GreetWithWinnings(ctx, "John", 1234567)
 //	if err != nil {
 //	    if errors.Is(err, greeting.ErrContextDeadlineExceeded) {
 //	        log.Println("Operation timed out")
@@ -43,12 +52,105 @@
 //	    }
 //	}
 //
+// # Greeter Interface
+//
+// The package-level functions use DefaultGreeter. Applications that need several
+// configurations, or want to inject and decorate greeters, can depend on the
+// Greeter interface and create their own instances with NewGreeter:
+//
+//	g, err := greeting.NewGreeter(
+//	    greeting.WithTemplate("Welcome back, {name}!"),
+//	    greeting.WithDefaultLocale("pt-BR"),
+//	    greeting.WithLogger(myLogger),
+//	    greeting.WithProcessingDelay(0),
+//	)
+//	message, err := g.Greet(ctx, "John")
+//
+// GreeterFunc adapts any function with Greet's signature to the Greeter interface.
+//
+// # Tracing
+//
+// Every greeting is recorded as a span of the tracing package, named after the
+// operation (such as "greeting.Greet"), with "greeting.process" and
+// "greeting.render" child spans that break down where the time went. Spans are
+// recorded by tracing.DefaultTracer, which is disabled until an exporter is
+// configured, or by the tracer passed to WithTracer:
+//
+//	exporter := tracing.NewInMemoryExporter()
+//	g, _ := greeting.NewGreeter(greeting.WithTracer(tracing.NewTracer(exporter)))
+//	g.Greet(ctx, "John")
+//	for _, span := range exporter.Spans() {
+//	    fmt.Println(span.Name, span.Duration())
+//	}
+//
+// # Processing Delay and Clocks
+//
+// Greeters simulate DefaultProcessingDelay of processing to demonstrate context
+// handling. The delay is configured with WithProcessingDelay, where zero disables
+// it, and measured with an injectable Clock. SystemClock is used by default;
+// ManualClock only moves when told to, so tests can advance time deterministically
+// and exercise cancellation paths without sleeping:
+//
+//	clock := greeting.NewManualClock(time.Now())
+//	g, _ := greeting.NewGreeter(greeting.WithClock(clock))
+//	go func() {
+//	    clock.BlockUntil(1) // Wait until the greeter is processing
+//	    cancel()            // or clock.Advance(greeting.DefaultProcessingDelay)
+//	}()
+//	_, err := g.Greet(ctx, "John") // err is ErrContextCanceled
+//
+// # Batch Greetings
+//
+// GreetBatch greets many names with a bounded pool of workers. Results are
+// returned in input order, each carrying its own error, and the batch stops early
+// when the context is canceled or its deadline is exceeded:
+//
+//	results, err := greeting.GreetBatch(ctx, names, greeting.BatchOptions{Concurrency: 16})
+//
+// Every greeting logs two informational messages. To keep large batches from
+// flooding the logs, give the greeter a logger that samples its messages:
+//
+//	sampled := logger.NewContextLogger(nil, logger.WithSampler(logger.NewSampler(10, 100, time.Second)))
+//	g, err := greeting.NewGreeter(greeting.WithLogger(sampled))
+//	results, err := greeting.GreetBatch(ctx, names, greeting.BatchOptions{Greeter: g})
+//	sampled.FlushSampling()
+//
+// For inputs that are too large to hold in memory, GreetStream and GreetChan read
+// names lazily from an iterator or a channel and yield results as they complete,
+// or in input order if StreamOptions.Ordered is set. A slow consumer slows down the
+// reading of names, and breaking out of the loop stops the stream:
+//
+//	for result, err := range greeting.GreetChan(ctx, names, greeting.StreamOptions{}) {
+//	    // ...
+//	}
+//
+// # Middleware
+//
+// Cross-cutting behavior is added by wrapping a greeting function with Middleware.
+// Chain applies middleware in order, outermost first, and returns a function with
+// Greet's signature:
+//
+//	greet := greeting.Chain(greeting.Greet,
+//	    greeting.NormalizeMiddleware(),
+//	    greeting.LoggingMiddleware(nil),
+//	    greeting.RetryMiddleware(greeting.RetryConfig{MaxAttempts: 3, Backoff: 10 * time.Millisecond}),
+//	    greeting.RateLimitMiddleware(greeting.RateLimitConfig{Rate: 100, Burst: 10}),
+//	)
+//
+// The package provides logging, timing, input normalization, caching, retry and
+// rate limiting middleware. Rate-limited calls fail with ErrRateLimited unless
+// the limiter is configured to wait.
+//
 // # Error Handling
 //
 // The package defines several error types to help with error handling:
 //
 // - ErrInvalidName: Returned when the provided name is empty
-// - ErrInvalidWinnings: Returned when the provided winnings amount is negative
+// - ErrInvalidWinnings: Returned when the provided winnings amount exceeds MaxWinnings
+// - ErrUnknownCurrency: Returned when a currency code is not registered
+// - ErrInvalidLocale: Returned when a locale is not a well-formed BCP 47 language tag
+// - ErrMessageNotFound: Returned when no message bundle contains the requested message
+// - ErrRateLimited: Returned when a rate-limited greeting function has no capacity left
 // - ErrContextCanceled: Returned when the context is canceled during processing
 // - ErrContextDeadlineExceeded: Returned when the context deadline is exceeded
 //
@@ -58,17 +160,68 @@
 //	    // Handle invalid name error
 //	}
 //
+// The greeting functions return these errors as *Error values that record the
+// failed operation, an ErrorCode, the offending input and the underlying cause,
+// such as the original ctx.Err(). An *Error still matches its sentinel with
+// errors.Is, and its code maps to HTTP and gRPC status codes:
+//
+//	var gerr *greeting.Error
+//	if errors.As(err, &gerr) {
+//	    log.Printf("%s failed: code=%s input=%q", gerr.Op, gerr.Code, gerr.Input)
+//	}
+//	status := greeting.CodeOf(err).HTTPStatus() // e.g. 400 for ErrInvalidName
+//
 // # Monetary Amount Formatting
 //
 // The package uses the go-humanize library to format monetary amounts in a
 // human-readable way, with proper comma separation and decimal places.
 // For example, a winnings value of 1234567 (cents) will be formatted as $12,345.67.
 //
+// # Multiple Currencies
+//
+// Prizes in other currencies are represented by the Money type, which pairs an
+// amount in minor units with a Currency. Each Currency knows its ISO 4217 code,
+// minor-unit exponent, symbol placement and digit grouping, so the same amount
+// renders differently depending on the currency:
+//
+//	jpy, _ := greeting.NewMoney(1234567, "JPY") // ¥1,234,567
+//	eur, _ := greeting.NewMoney(1234567, "EUR") // 12.345,67 €
+//	usd, _ := greeting.NewMoney(1234567, "USD") // $12,345.67
+//	message, err := greeting.GreetWithMoney(ctx, "Yuki", jpy)
+//
+// Additional currencies can be added with RegisterCurrency.
+//
+// # Localization
+//
+// Greeting messages are rendered from a Catalog of per-locale message bundles.
+// The locale is selected from the context, the same way logger.WithRequestID
+// attaches request IDs:
+//
+//	ctx = greeting.WithLocale(ctx, "pt-BR")
+//	message, err := greeting.Greet(ctx, "João")
+//	// Output: Olá João!
+//
+// Locales are BCP 47 language tags. Each message is resolved along a fallback
+// chain that drops subtags one at a time and ends with the catalog's default
+// locale, so "pt-BR" tries "pt-BR", then "pt", then "en". The DefaultCatalog
+// ships with bundles embedded in the package; use LoadCatalog or LoadCatalogDir
+// to load bundles named "<locale>.json" from any fs.FS or directory.
+//
+// Message templates use the ICU MessageFormat subset implemented by the msgfmt
+// subpackage, so translations can choose plural and select branches:
+//
+//	"You have won {count, plural, one {# prize} other {# prizes}}"
+//
+// Templates are compiled when a bundle is loaded; syntax errors identify the
+// locale, message key, line and column of the problem.
+//
 // # Parameters
 //
-// The Greet function takes the following parameters:
+// The GreetWithWinnings function takes the following parameters:
 //
 // - ctx (context.Context): The context for the operation, which can be used for cancellation and timeouts
 // - name (string): The name of the person to greet (must not be empty)
 // - winnings (uint64): The amount of winnings in cents (e.g., 1234567 = $12,345.67)
+//
+// Greet takes the same parameters without the winnings amount.
 package greeting
@@ -0,0 +1,219 @@
+package greeting
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ErrorCode classifies the errors returned by the greeting package. Every code
+// except CodeUnknown corresponds to one of the package's sentinel errors.
+type ErrorCode int
+
+// Error codes, one per sentinel error.
+const (
+	// CodeUnknown classifies errors that do not match any sentinel error.
+	CodeUnknown ErrorCode = iota
+	// CodeInvalidName corresponds to ErrInvalidName.
+	CodeInvalidName
+	// CodeInvalidWinnings corresponds to ErrInvalidWinnings.
+	CodeInvalidWinnings
+	// CodeUnknownCurrency corresponds to ErrUnknownCurrency.
+	CodeUnknownCurrency
+	// CodeInvalidLocale corresponds to ErrInvalidLocale.
+	CodeInvalidLocale
+	// CodeMessageNotFound corresponds to ErrMessageNotFound.
+	CodeMessageNotFound
+	// CodeRateLimited corresponds to ErrRateLimited.
+	CodeRateLimited
+	// CodeCanceled corresponds to ErrContextCanceled.
+	CodeCanceled
+	// CodeDeadlineExceeded corresponds to ErrContextDeadlineExceeded.
+	CodeDeadlineExceeded
+)
+
+// codeInfo describes an error code.
+type codeInfo struct {
+	name     string
+	sentinel error
+	http     int
+	grpc     uint32
+}
+
+// statusClientClosedRequest is the non-standard HTTP status used by proxies such
+// as nginx when the client goes away before the response is written.
+const statusClientClosedRequest = 499
+
+// codes maps every ErrorCode to its name, sentinel error and status codes. The
+// gRPC values are those of google.golang.org/grpc/codes.
+var codes = map[ErrorCode]codeInfo{
+	CodeUnknown:          {"Unknown", nil, http.StatusInternalServerError, 2},
+	CodeInvalidName:      {"InvalidName", ErrInvalidName, http.StatusBadRequest, 3},
+	CodeInvalidWinnings:  {"InvalidWinnings", ErrInvalidWinnings, http.StatusBadRequest, 3},
+	CodeUnknownCurrency:  {"UnknownCurrency", ErrUnknownCurrency, http.StatusBadRequest, 3},
+	CodeInvalidLocale:    {"InvalidLocale", ErrInvalidLocale, http.StatusBadRequest, 3},
+	CodeMessageNotFound:  {"MessageNotFound", ErrMessageNotFound, http.StatusNotFound, 5},
+	CodeRateLimited:      {"RateLimited", ErrRateLimited, http.StatusTooManyRequests, 8},
+	CodeCanceled:         {"Canceled", ErrContextCanceled, statusClientClosedRequest, 1},
+	CodeDeadlineExceeded: {"DeadlineExceeded", ErrContextDeadlineExceeded, http.StatusGatewayTimeout, 4},
+}
+
+// info returns the description of c. Unknown values are treated as CodeUnknown.
+func (c ErrorCode) info() codeInfo {
+	if info, ok := codes[c]; ok {
+		return info
+	}
+	return codes[CodeUnknown]
+}
+
+// String returns the name of the code, such as "InvalidName".
+func (c ErrorCode) String() string {
+	if _, ok := codes[c]; !ok {
+		return "ErrorCode(" + strconv.Itoa(int(c)) + ")"
+	}
+	return c.info().name
+}
+
+// Sentinel returns the sentinel error corresponding to the code, or nil for CodeUnknown.
+func (c ErrorCode) Sentinel() error {
+	return c.info().sentinel
+}
+
+// HTTPStatus returns the HTTP status code that best describes the code. Invalid
+// input maps to 400, a missing message to 404, rate limiting to 429, a deadline
+// to 504 and cancellation to the non-standard 499 (Client Closed Request).
+// Unknown errors map to 500.
+func (c ErrorCode) HTTPStatus() int {
+	return c.info().http
+}
+
+// GRPCCode returns the gRPC status code that best describes the code, as the
+// numeric value of google.golang.org/grpc/codes.Code:
+//
+//	status.Error(codes.Code(e.Code.GRPCCode()), e.Error())
+func (c ErrorCode) GRPCCode() uint32 {
+	return c.info().grpc
+}
+
+// CodeOf classifies any error returned by the greeting package. It returns the
+// code of the first *Error in err's chain; otherwise it matches err against the
+// sentinel errors and the context package's errors. Nil and unrecognized errors
+// yield CodeUnknown.
+//
+// # Example
+//
+//	if err != nil {
+//	    http.Error(w, err.Error(), greeting.CodeOf(err).HTTPStatus())
+//	}
+func CodeOf(err error) ErrorCode {
+	if err == nil {
+		return CodeUnknown
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	for code := CodeInvalidName; code <= CodeDeadlineExceeded; code++ {
+		if errors.Is(err, code.Sentinel()) {
+			return code
+		}
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeDeadlineExceeded
+	}
+	return CodeUnknown
+}
+
+// Error is the detailed error returned by the greeting functions. It records the
+// failed operation, a code that classifies the failure, the offending input and
+// the underlying cause, if any.
+//
+// # Usage
+//
+// An *Error matches the sentinel error of its code with errors.Is, so existing
+// checks such as errors.Is(err, greeting.ErrInvalidName) keep working. The cause
+// is available through errors.Unwrap, so a canceled greeting also matches
+// context.Canceled. Use errors.As to access the details:
+//
+//	var gerr *greeting.Error
+//	if errors.As(err, &gerr) {
+//	    log.Printf("op=%s code=%s input=%q", gerr.Op, gerr.Code, gerr.Input)
+//	}
+type Error struct {
+	// Op is the operation that failed, such as "Greet" or "GreetBatch".
+	Op string
+	// Code classifies the failure.
+	Code ErrorCode
+	// Input is the offending input, if any, such as the rejected winnings amount.
+	Input string
+	// Err is the underlying cause, such as the context's error. It may be nil.
+	Err error
+}
+
+// Error returns a message of the form "Op: description" followed by the quoted
+// input and the cause, when present.
+func (e *Error) Error() string {
+	var b strings.Builder
+	if e.Op != "" {
+		b.WriteString(e.Op)
+		b.WriteString(": ")
+	}
+
+	sentinel := e.Code.Sentinel()
+	switch {
+	case sentinel == nil && e.Err == nil:
+		b.WriteString("unknown error")
+	case sentinel == nil:
+		b.WriteString(e.Err.Error())
+	case e.Err != nil && errors.Is(e.Err, sentinel):
+		// The cause already describes the failure.
+		b.WriteString(e.Err.Error())
+	default:
+		b.WriteString(sentinel.Error())
+		if e.Input != "" {
+			b.WriteString(" ")
+			b.WriteString(strconv.Quote(e.Input))
+		}
+		if e.Err != nil {
+			b.WriteString(": ")
+			b.WriteString(e.Err.Error())
+		}
+	}
+	return b.String()
+}
+
+// Unwrap returns the underlying cause.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the sentinel error of e's code.
+func (e *Error) Is(target error) bool {
+	sentinel := e.Code.Sentinel()
+	return sentinel != nil && target == sentinel
+}
+
+// newError creates an *Error for the given operation and code.
+func newError(op string, code ErrorCode, input string, cause error) *Error {
+	return &Error{Op: op, Code: code, Input: input, Err: cause}
+}
+
+// mapContextError maps a context error to an *Error with code CodeCanceled or
+// CodeDeadlineExceeded that wraps the original error. Other errors are wrapped
+// with CodeUnknown.
+func mapContextError(op string, err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return newError(op, CodeCanceled, "", err)
+	case errors.Is(err, context.DeadlineExceeded):
+		return newError(op, CodeDeadlineExceeded, "", err)
+	default:
+		return newError(op, CodeUnknown, "", err)
+	}
+}
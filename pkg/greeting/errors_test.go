@@ -0,0 +1,168 @@
+package greeting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorMatchesSentinel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Greet(ctx, "John")
+	assert.True(t, errors.Is(err, ErrContextCanceled), "Expected ErrContextCanceled, got %v", err)
+	assert.True(t, errors.Is(err, context.Canceled), "The original context error should be preserved")
+	assert.False(t, errors.Is(err, ErrContextDeadlineExceeded))
+
+	var gerr *Error
+	if assert.True(t, errors.As(err, &gerr)) {
+		assert.Equal(t, "Greet", gerr.Op)
+		assert.Equal(t, CodeCanceled, gerr.Code)
+		assert.Equal(t, context.Canceled, gerr.Err)
+	}
+	assert.Equal(t, "Greet: operation was canceled by context: context canceled", err.Error())
+}
+
+func TestErrorDetails(t *testing.T) {
+	g, err := NewGreeter(WithProcessingDelay(0))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		call    func() error
+		code    ErrorCode
+		op      string
+		input   string
+		message string
+	}{
+		{
+			name:    "empty name",
+			call:    func() error { _, err := g.Greet(context.Background(), ""); return err },
+			code:    CodeInvalidName,
+			op:      "Greet",
+			message: "Greet: name cannot be empty",
+		},
+		{
+			name:    "winnings out of range",
+			call:    func() error { _, err := g.GreetWithWinnings(context.Background(), "John", MaxWinnings+1); return err },
+			code:    CodeInvalidWinnings,
+			op:      "GreetWithWinnings",
+			input:   "100000000001",
+			message: `GreetWithWinnings: winnings amount is out of range "100000000001"`,
+		},
+		{
+			name: "negative prize",
+			call: func() error {
+				_, err := g.GreetWithMoney(context.Background(), "John", Money{Amount: -1, Currency: Currency{Code: "USD"}})
+				return err
+			},
+			code:    CodeInvalidWinnings,
+			op:      "GreetWithMoney",
+			input:   "-1",
+			message: `GreetWithMoney: winnings amount is out of range "-1"`,
+		},
+		{
+			name:    "missing currency",
+			call:    func() error { _, err := g.GreetWithMoney(context.Background(), "John", Money{Amount: 1}); return err },
+			code:    CodeUnknownCurrency,
+			op:      "GreetWithMoney",
+			message: "GreetWithMoney: unknown currency: missing currency",
+		},
+		{
+			name: "message not found",
+			call: func() error {
+				empty, _ := NewCatalog("en")
+				g, _ := NewGreeter(WithCatalog(empty), WithDefaultLocale("en"), WithProcessingDelay(0))
+				_, err := g.Greet(context.Background(), "John")
+				return err
+			},
+			code:    CodeMessageNotFound,
+			op:      "Greet",
+			message: `Greet: message not found: "greeting" for locale en`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+
+			var gerr *Error
+			if assert.True(t, errors.As(err, &gerr), "Expected *Error, got %T", err) {
+				assert.Equal(t, tt.op, gerr.Op)
+				assert.Equal(t, tt.code, gerr.Code)
+				assert.Equal(t, tt.input, gerr.Input)
+			}
+			assert.True(t, errors.Is(err, tt.code.Sentinel()))
+			assert.Equal(t, tt.message, err.Error())
+		})
+	}
+}
+
+func TestErrorDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := Greet(ctx, "John")
+	assert.True(t, errors.Is(err, ErrContextDeadlineExceeded))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, CodeDeadlineExceeded, CodeOf(err))
+}
+
+func TestCodeOf(t *testing.T) {
+	assert.Equal(t, CodeUnknown, CodeOf(nil))
+	assert.Equal(t, CodeUnknown, CodeOf(errors.New("boom")))
+	assert.Equal(t, CodeInvalidName, CodeOf(ErrInvalidName))
+	assert.Equal(t, CodeInvalidLocale, CodeOf(fmt.Errorf("wrapped: %w", ErrInvalidLocale)))
+	assert.Equal(t, CodeCanceled, CodeOf(context.Canceled))
+	assert.Equal(t, CodeDeadlineExceeded, CodeOf(fmt.Errorf("wrapped: %w", context.DeadlineExceeded)))
+	assert.Equal(t, CodeRateLimited, CodeOf(fmt.Errorf("wrapped: %w", &Error{Code: CodeRateLimited})))
+
+	_, err := ParseTag("not a tag")
+	assert.Equal(t, CodeInvalidLocale, CodeOf(err))
+	_, err = LookupCurrency("XXX")
+	assert.Equal(t, CodeUnknownCurrency, CodeOf(err))
+}
+
+func TestErrorCodeMappings(t *testing.T) {
+	tests := []struct {
+		code ErrorCode
+		name string
+		http int
+		grpc uint32
+	}{
+		{CodeUnknown, "Unknown", http.StatusInternalServerError, 2},
+		{CodeInvalidName, "InvalidName", http.StatusBadRequest, 3},
+		{CodeInvalidWinnings, "InvalidWinnings", http.StatusBadRequest, 3},
+		{CodeUnknownCurrency, "UnknownCurrency", http.StatusBadRequest, 3},
+		{CodeInvalidLocale, "InvalidLocale", http.StatusBadRequest, 3},
+		{CodeMessageNotFound, "MessageNotFound", http.StatusNotFound, 5},
+		{CodeRateLimited, "RateLimited", http.StatusTooManyRequests, 8},
+		{CodeCanceled, "Canceled", 499, 1},
+		{CodeDeadlineExceeded, "DeadlineExceeded", http.StatusGatewayTimeout, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.name, tt.code.String())
+			assert.Equal(t, tt.http, tt.code.HTTPStatus())
+			assert.Equal(t, tt.grpc, tt.code.GRPCCode())
+		})
+	}
+
+	assert.Equal(t, "ErrorCode(42)", ErrorCode(42).String())
+	assert.Equal(t, http.StatusInternalServerError, ErrorCode(42).HTTPStatus())
+	assert.Nil(t, ErrorCode(42).Sentinel())
+}
+
+func TestErrorMessage(t *testing.T) {
+	assert.Equal(t, "unknown error", (&Error{}).Error())
+	assert.Equal(t, "Op: boom", (&Error{Op: "Op", Err: errors.New("boom")}).Error())
+	assert.Equal(t, `RateLimitMiddleware: rate limit exceeded "John"`, (&Error{Op: "RateLimitMiddleware", Code: CodeRateLimited, Input: "John"}).Error())
+	assert.False(t, errors.Is(&Error{Code: CodeUnknown}, ErrInvalidName))
+}
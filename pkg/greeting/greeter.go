@@ -0,0 +1,364 @@
+package greeting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/abitofhelp/bazel8_go/pkg/greeting/msgfmt"
+	"github.com/abitofhelp/bazel8_go/pkg/logger"
+	"github.com/abitofhelp/bazel8_go/pkg/tracing"
+)
+
+// DefaultProcessingDelay is the simulated processing time of a greeter that was
+// not configured with WithProcessingDelay.
+const DefaultProcessingDelay = 100 * time.Millisecond
+
+// Greeter generates personalized greeting messages.
+//
+// The package-level Greet function and StandardGreeter both satisfy this interface
+// (the former through GreeterFunc), so applications can depend on Greeter and
+// inject custom or decorated implementations, for example in tests.
+type Greeter interface {
+	// Greet returns a greeting message for the given name.
+	Greet(ctx context.Context, name string) (string, error)
+}
+
+// GreeterFunc is an adapter that allows ordinary functions with Greet's signature
+// to be used as a Greeter.
+//
+// # Example
+//
+//	var g greeting.Greeter = greeting.GreeterFunc(greeting.Greet)
+type GreeterFunc func(ctx context.Context, name string) (string, error)
+
+// Greet calls f(ctx, name).
+func (f GreeterFunc) Greet(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// StandardGreeter is the default Greeter implementation. It validates its input,
+// honors context cancellation, simulates processing and renders the greeting
+// from a message template or catalog.
+//
+// # Usage
+//
+// Create instances with NewGreeter. Several differently configured greeters can
+// be used at the same time; the package-level functions use DefaultGreeter.
+// A StandardGreeter is safe for concurrent use by multiple goroutines.
+type StandardGreeter struct {
+	// template, if set, replaces the catalog message for every greeting.
+	template *msgfmt.Message
+	// catalog provides the localized messages; nil means DefaultCatalog.
+	catalog *Catalog
+	// locale is used when the context carries no locale; empty means the catalog default.
+	locale Tag
+	// clock measures the simulated processing time.
+	clock Clock
+	// logger receives the greeter's log messages; nil means logger.DefaultLogger.
+	logger *logger.ContextLogger
+	// tracer records the spans of the greeter's operations; nil means tracing.DefaultTracer.
+	tracer *tracing.Tracer
+	// delay is the simulated processing time.
+	delay time.Duration
+}
+
+// Option configures a StandardGreeter created by NewGreeter.
+type Option func(*greeterConfig)
+
+// greeterConfig collects the options passed to NewGreeter.
+type greeterConfig struct {
+	template string
+	catalog  *Catalog
+	locale   string
+	clock    Clock
+	logger   *logger.ContextLogger
+	tracer   *tracing.Tracer
+	delay    time.Duration
+}
+
+// WithTemplate renders every greeting from the given ICU MessageFormat pattern
+// instead of the catalog. The pattern receives the {name} argument and, for
+// winnings greetings, the {amount} argument.
+func WithTemplate(pattern string) Option {
+	return func(c *greeterConfig) { c.template = pattern }
+}
+
+// WithCatalog renders greetings from the given catalog instead of DefaultCatalog.
+func WithCatalog(catalog *Catalog) Option {
+	return func(c *greeterConfig) { c.catalog = catalog }
+}
+
+// WithDefaultLocale sets the locale used when the context does not carry one
+// (see WithLocale). It also selects the plural rules of a WithTemplate pattern.
+func WithDefaultLocale(locale string) Option {
+	return func(c *greeterConfig) { c.locale = locale }
+}
+
+// WithClock sets the clock used to measure the simulated processing time.
+func WithClock(clock Clock) Option {
+	return func(c *greeterConfig) { c.clock = clock }
+}
+
+// WithLogger sets the logger that receives the greeter's log messages.
+func WithLogger(l *logger.ContextLogger) Option {
+	return func(c *greeterConfig) { c.logger = l }
+}
+
+// WithTracer sets the tracer that records the spans of the greeter's operations.
+// Every greeting is recorded as a "greeting.<operation>" span, such as
+// "greeting.Greet", with "greeting.process" and "greeting.render" child spans
+// for the simulated processing and the rendering of the message.
+func WithTracer(t *tracing.Tracer) Option {
+	return func(c *greeterConfig) { c.tracer = t }
+}
+
+// WithProcessingDelay sets the simulated processing time. A zero or negative
+// delay disables the simulation entirely.
+func WithProcessingDelay(d time.Duration) Option {
+	return func(c *greeterConfig) { c.delay = max(d, 0) }
+}
+
+// NewGreeter creates a StandardGreeter configured with the given options.
+//
+// # Parameters
+//
+// - opts: Options such as WithTemplate, WithCatalog, WithDefaultLocale, WithClock,
+// WithLogger, WithTracer and WithProcessingDelay. Without options, the greeter behaves like
+// the package-level Greet function.
+//
+// # Return Values
+//
+// - *StandardGreeter: The configured greeter.
+//
+// - error: An error if an option is invalid, such as a malformed locale
+// (ErrInvalidLocale) or a template that fails to compile (*msgfmt.SyntaxError).
+//
+// # Example
+//
+//	g, err := greeting.NewGreeter(
+//	    greeting.WithTemplate("Welcome back, {name}!"),
+//	    greeting.WithProcessingDelay(0),
+//	)
+//	if err != nil {
+//	    log.Fatalf("Error: %v", err)
+//	}
+//	message, err := g.Greet(ctx, "John")
+//	// message: "Welcome back, John!"
+func NewGreeter(opts ...Option) (*StandardGreeter, error) {
+	cfg := greeterConfig{clock: SystemClock, delay: DefaultProcessingDelay}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	g := &StandardGreeter{
+		catalog: cfg.catalog,
+		clock:   cfg.clock,
+		logger:  cfg.logger,
+		tracer:  cfg.tracer,
+		delay:   cfg.delay,
+	}
+	if g.clock == nil {
+		g.clock = SystemClock
+	}
+
+	if cfg.locale != "" {
+		tag, err := ParseTag(cfg.locale)
+		if err != nil {
+			return nil, err
+		}
+		g.locale = tag
+	}
+
+	if cfg.template != "" {
+		lang := g.locale
+		if lang == "" {
+			lang = DefaultLocale
+		}
+		tmpl, err := msgfmt.Compile(string(lang), cfg.template)
+		if err != nil {
+			return nil, fmt.Errorf("greeting template: %w", err)
+		}
+		g.template = tmpl
+	}
+
+	return g, nil
+}
+
+// DefaultGreeter is the greeter used by the package-level greeting functions.
+// It renders messages from DefaultCatalog, logs to logger.DefaultLogger and
+// simulates DefaultProcessingDelay of processing. Replace it to reconfigure the
+// package-level functions, for example to remove the processing delay:
+//
+//	greeting.DefaultGreeter, _ = greeting.NewGreeter(greeting.WithProcessingDelay(0))
+//
+// Reassigning the variable is not synchronized: replace it only during
+// initialization, such as in main or TestMain, before any goroutine calls the
+// package-level functions. Code that needs another configuration later should
+// create its own greeter with NewGreeter instead.
+var DefaultGreeter = mustNewGreeter()
+
+// mustNewGreeter creates the DefaultGreeter. Its configuration contains no
+// user input, so failing to create it is a programming error.
+func mustNewGreeter() *StandardGreeter {
+	g, err := NewGreeter()
+	if err != nil {
+		panic(fmt.Sprintf("greeting: default greeter: %v", err))
+	}
+	return g
+}
+
+// Greet returns a greeting message for the given name. It behaves like the
+// package-level Greet function, using the greeter's configuration.
+func (g *StandardGreeter) Greet(ctx context.Context, name string) (string, error) {
+	return g.greet(ctx, "Greet", name, MessageGreeting, map[string]any{"name": name})
+}
+
+// GreetWithWinnings returns a greeting message that announces the recipient's
+// winnings in cents. It behaves like the package-level GreetWithWinnings function,
+// using the greeter's configuration.
+func (g *StandardGreeter) GreetWithWinnings(ctx context.Context, name string, winnings uint64) (string, error) {
+	if ctx.Err() != nil {
+		return "", g.contextError(ctx, "GreetWithWinnings", "before processing")
+	}
+	if winnings > MaxWinnings {
+		g.log().Warning(ctx, "Invalid winnings provided: %d", winnings)
+		return "", newError("GreetWithWinnings", CodeInvalidWinnings, strconv.FormatUint(winnings, 10), nil)
+	}
+
+	return g.greet(ctx, "GreetWithWinnings", name, MessageGreetingWinnings, map[string]any{
+		"name":   name,
+		"amount": formatWinnings(winnings),
+	})
+}
+
+// GreetWithMoney returns a greeting message that announces a prize in any
+// registered currency. It behaves like the package-level GreetWithMoney function,
+// using the greeter's configuration.
+func (g *StandardGreeter) GreetWithMoney(ctx context.Context, name string, prize Money) (string, error) {
+	if ctx.Err() != nil {
+		return "", g.contextError(ctx, "GreetWithMoney", "before processing")
+	}
+	if err := prize.Currency.validate(); err != nil {
+		g.log().Warning(ctx, "Invalid prize provided: %v", err)
+		return "", newError("GreetWithMoney", CodeUnknownCurrency, "", err)
+	}
+	if prize.Amount < 0 {
+		g.log().Warning(ctx, "Invalid prize provided: %d", prize.Amount)
+		return "", newError("GreetWithMoney", CodeInvalidWinnings, strconv.FormatInt(prize.Amount, 10), nil)
+	}
+
+	return g.greet(ctx, "GreetWithMoney", name, MessageGreetingWinnings, map[string]any{
+		"name":   name,
+		"amount": prize.String(),
+	})
+}
+
+// greet runs the workflow shared by every greeting variant in a span named after
+// the operation op. See generate.
+func (g *StandardGreeter) greet(ctx context.Context, op, name, key string, args map[string]any) (string, error) {
+	ctx, span := g.startSpan(ctx, "greeting."+op, tracing.String("greeting.message", key))
+	defer span.End()
+
+	message, err := g.generate(ctx, op, name, key, args)
+	span.RecordError(err)
+	return message, err
+}
+
+// generate checks the context, validates the name, simulates processing and
+// finally renders the message identified by key. Rendering only happens once all
+// checks have passed. Errors are reported as *Error values for the operation op.
+func (g *StandardGreeter) generate(ctx context.Context, op, name, key string, args map[string]any) (string, error) {
+	// Check if context is already canceled or deadline exceeded
+	if ctx.Err() != nil {
+		return "", g.contextError(ctx, op, "before processing")
+	}
+
+	// Validate input parameters
+	if name == "" {
+		g.log().Warning(ctx, "Invalid name provided: empty string")
+		return "", newError(op, CodeInvalidName, name, nil)
+	}
+
+	g.log().Info(ctx, "Generating greeting for '%s'", logger.Sensitive(name))
+
+	// Simulate some processing time to demonstrate context handling
+	if g.delay > 0 {
+		_, processSpan := g.startSpan(ctx, "greeting.process")
+		select {
+		case <-ctx.Done():
+			processSpan.End()
+			return "", g.contextError(ctx, op, "during processing")
+		case <-g.clock.After(g.delay):
+			// Continue processing
+			processSpan.End()
+		}
+	}
+
+	renderCtx, renderSpan := g.startSpan(ctx, "greeting.render")
+	message, err := g.render(renderCtx, key, args)
+	renderSpan.RecordError(err)
+	renderSpan.End()
+	if err != nil {
+		g.log().Error(ctx, "Failed to render greeting: %v", err)
+		return "", newError(op, CodeOf(err), "", err)
+	}
+	message += "\n"
+
+	g.log().Info(ctx, "Generated greeting: %s", logger.Sensitive(message))
+	return message, nil
+}
+
+// render formats the greeting from the greeter's template, if any, or from the
+// catalog message identified by key in the locale selected by the context.
+func (g *StandardGreeter) render(ctx context.Context, key string, args map[string]any) (string, error) {
+	if g.template != nil {
+		return g.template.Format(args)
+	}
+
+	catalog := g.catalog
+	if catalog == nil {
+		catalog = DefaultCatalog
+	}
+
+	locale, ok := LocaleFromContext(ctx)
+	if !ok {
+		locale = g.locale
+	}
+	return catalog.Format(locale, key, args)
+}
+
+// contextError logs the context's error and wraps it in an *Error for the operation op.
+// The phase describes when the error was detected (e.g. "before processing").
+func (g *StandardGreeter) contextError(ctx context.Context, op, phase string) error {
+	if errors.Is(ctx.Err(), context.Canceled) {
+		g.log().Warning(ctx, "Context was canceled %s: %v", phase, ctx.Err())
+	} else if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		g.log().Warning(ctx, "Context deadline exceeded %s: %v", phase, ctx.Err())
+	} else {
+		// For any other context error
+		g.log().Error(ctx, "Context error %s: %v", phase, ctx.Err())
+	}
+	return mapContextError(op, ctx.Err())
+}
+
+// log returns the greeter's logger. Greeters without a configured logger resolve
+// logger.DefaultLogger on every call so that replacing it takes effect immediately.
+func (g *StandardGreeter) log() *logger.ContextLogger {
+	if g.logger != nil {
+		return g.logger
+	}
+	return logger.DefaultLogger
+}
+
+// startSpan starts a span with the greeter's tracer. Greeters without a
+// configured tracer resolve tracing.DefaultTracer on every call so that replacing
+// it takes effect immediately.
+func (g *StandardGreeter) startSpan(ctx context.Context, name string, attrs ...tracing.Attribute) (context.Context, *tracing.Span) {
+	if g.tracer != nil {
+		return g.tracer.Start(ctx, name, attrs...)
+	}
+	return tracing.Start(ctx, name, attrs...)
+}
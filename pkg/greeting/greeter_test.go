@@ -0,0 +1,186 @@
+package greeting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/bazel8_go/pkg/greeting/msgfmt"
+	"github.com/abitofhelp/bazel8_go/pkg/logger"
+	"github.com/abitofhelp/bazel8_go/pkg/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewGreeter(t *testing.T) {
+	g, err := NewGreeter()
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultProcessingDelay, g.delay)
+	assert.Equal(t, SystemClock, g.clock)
+
+	_, err = NewGreeter(WithDefaultLocale("not a locale"))
+	assert.True(t, errors.Is(err, ErrInvalidLocale), "Expected ErrInvalidLocale, got %v", err)
+
+	_, err = NewGreeter(WithTemplate("Howdy {name"))
+	var syntaxErr *msgfmt.SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr), "Expected *msgfmt.SyntaxError, got %v", err)
+
+	g, err = NewGreeter(WithProcessingDelay(-time.Second), WithClock(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), g.delay, "Negative delays should disable the simulation")
+	assert.Equal(t, SystemClock, g.clock, "A nil clock should fall back to SystemClock")
+}
+
+func TestStandardGreeterOptions(t *testing.T) {
+	var logOutput bytes.Buffer
+	ctxLogger := logger.NewContextLogger(log.New(&logOutput, "", 0))
+
+	catalog, err := NewCatalog("en")
+	assert.NoError(t, err)
+	assert.NoError(t, catalog.AddBundle("en", map[string]string{MessageGreeting: "Hi {name}"}))
+	assert.NoError(t, catalog.AddBundle("de", map[string]string{MessageGreeting: "Servus {name}"}))
+
+	templated, err := NewGreeter(
+		WithTemplate("Welcome back, {name}!"),
+		WithProcessingDelay(0),
+		WithLogger(ctxLogger),
+	)
+	assert.NoError(t, err)
+
+	localized, err := NewGreeter(
+		WithCatalog(catalog),
+		WithDefaultLocale("de-AT"),
+		WithProcessingDelay(0),
+		WithLogger(ctxLogger),
+	)
+	assert.NoError(t, err)
+
+	message, err := templated.Greet(context.Background(), "John")
+	assert.NoError(t, err)
+	assert.Equal(t, "Welcome back, John!\n", message)
+
+	message, err = localized.Greet(context.Background(), "John")
+	assert.NoError(t, err)
+	assert.Equal(t, "Servus John\n", message, "The greeter's default locale should be used")
+
+	message, err = localized.Greet(WithLocale(context.Background(), "en"), "John")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi John\n", message, "The context locale should take precedence")
+
+	_, err = templated.Greet(context.Background(), "")
+	assert.True(t, errors.Is(err, ErrInvalidName))
+
+	assert.Contains(t, logOutput.String(), "INFO: Generating greeting for '[REDACTED]'")
+	assert.Contains(t, logOutput.String(), "WARNING: Invalid name provided: empty string")
+}
+
+func TestStandardGreeterProcessingDelay(t *testing.T) {
+	clock := NewManualClock(time.Unix(0, 0))
+	g, err := NewGreeter(WithClock(clock), WithProcessingDelay(time.Second))
+	assert.NoError(t, err)
+
+	t.Run("completes once the delay has elapsed", func(t *testing.T) {
+		done := make(chan error, 1)
+		go func() {
+			_, err := g.Greet(context.Background(), "John")
+			done <- err
+		}()
+
+		clock.BlockUntil(1)
+		clock.Advance(999 * time.Millisecond)
+		assert.Len(t, done, 0, "The greeting should still be processing")
+
+		clock.Advance(time.Millisecond)
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("canceled during processing", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			_, err := g.Greet(ctx, "John")
+			done <- err
+		}()
+
+		clock.BlockUntil(1)
+		cancel()
+		assert.True(t, errors.Is(<-done, ErrContextCanceled))
+		clock.Advance(time.Second) // Release the abandoned waiter
+	})
+
+	t.Run("zero delay does not wait", func(t *testing.T) {
+		immediate, err := NewGreeter(WithClock(clock), WithProcessingDelay(0))
+		assert.NoError(t, err)
+
+		message, err := immediate.Greet(context.Background(), "John")
+		assert.NoError(t, err)
+		assert.Equal(t, "Howdy John!\n", message)
+		assert.Equal(t, 0, clock.Waiters(), "No timer should be started")
+	})
+}
+
+func TestStandardGreeterTemplateWithWinnings(t *testing.T) {
+	g, err := NewGreeter(
+		WithTemplate("{name} won {amount}"),
+		WithProcessingDelay(0),
+	)
+	assert.NoError(t, err)
+
+	message, err := g.GreetWithWinnings(context.Background(), "Ann", 150)
+	assert.NoError(t, err)
+	assert.Equal(t, "Ann won $1.50 USD\n", message)
+
+	_, err = g.Greet(context.Background(), "Ann")
+	assert.True(t, errors.Is(err, msgfmt.ErrMissingArgument), "Templates referencing {amount} need winnings")
+}
+
+func TestStandardGreeterTracing(t *testing.T) {
+	var logOutput bytes.Buffer
+	exporter := tracing.NewInMemoryExporter()
+	g, err := NewGreeter(
+		WithProcessingDelay(time.Nanosecond),
+		WithLogger(logger.NewContextLogger(log.New(&logOutput, "", 0))),
+		WithTracer(tracing.NewTracer(exporter)),
+	)
+	assert.NoError(t, err)
+
+	_, err = g.GreetWithWinnings(context.Background(), "John", 150)
+	assert.NoError(t, err)
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 3)
+	assert.Equal(t, []string{"greeting.process", "greeting.render", "greeting.GreetWithWinnings"},
+		[]string{spans[0].Name, spans[1].Name, spans[2].Name})
+	root := spans[2]
+	assert.Equal(t, []tracing.Attribute{tracing.String("greeting.message", MessageGreetingWinnings)}, root.Attributes)
+	for _, child := range spans[:2] {
+		assert.Equal(t, root.TraceID, child.TraceID)
+		assert.Equal(t, root.SpanID, child.ParentSpanID)
+		assert.False(t, child.Start.Before(root.Start) || child.End.After(root.End), "Children lie within their parent")
+	}
+	assert.Contains(t, logOutput.String(), "INFO: [trace_id="+root.TraceID.String()+" span_id="+root.SpanID.String()+"] Generating greeting for '[REDACTED]'")
+
+	exporter.Reset()
+	_, err = g.Greet(context.Background(), "")
+	assert.True(t, errors.Is(err, ErrInvalidName))
+	spans = exporter.Spans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, tracing.StatusError, spans[0].Status)
+	assert.Equal(t, err.Error(), spans[0].StatusMessage)
+}
+
+func TestGreeterFunc(t *testing.T) {
+	var g Greeter = GreeterFunc(func(ctx context.Context, name string) (string, error) {
+		return "Hello " + name, nil
+	})
+
+	message, err := g.Greet(context.Background(), "John")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello John", message)
+
+	// Both implementations satisfy the interface.
+	var _ Greeter = GreeterFunc(Greet)
+	var _ Greeter = DefaultGreeter
+}
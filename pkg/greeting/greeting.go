@@ -5,10 +5,6 @@
 import (
 	"context"
 	"errors"
-	"fmt"
-	"time"
-
-	"github.com/abitofhelp/bazel8_go/pkg/logger"
 )
 
 // Error definitions for the greeting package.
@@ -16,6 +12,21 @@
 	// ErrInvalidName is returned when the provided name is empty.
 	ErrInvalidName = errors.New("name cannot be empty")
 
+	// ErrInvalidWinnings is returned when the provided winnings amount is negative or cannot be represented.
+	ErrInvalidWinnings = errors.New("winnings amount is out of range")
+
+	// ErrUnknownCurrency is returned when a currency code is not registered.
+	ErrUnknownCurrency = errors.New("unknown currency")
+
+	// ErrInvalidLocale is returned when a locale is not a well-formed BCP 47 language tag.
+	ErrInvalidLocale = errors.New("invalid locale")
+
+	// ErrMessageNotFound is returned when no message bundle in a fallback chain contains a message.
+	ErrMessageNotFound = errors.New("message not found")
+
+	// ErrRateLimited is returned when a rate-limited greeting function has no capacity left.
+	ErrRateLimited = errors.New("rate limit exceeded")
+
 	// ErrContextCanceled is returned when the context is canceled during processing.
 	ErrContextCanceled = errors.New("operation was canceled by context")
 
@@ -73,49 +84,5 @@
 //	    // Handle other errors
 //	}
 func Greet(ctx context.Context, name string) (string, error) {
-	// Check if context is already canceled or deadline exceeded
-	if ctx.Err() != nil {
-		if errors.Is(ctx.Err(), context.Canceled) {
-			logger.DefaultLogger.Warning(ctx, "Context was canceled before processing: %v", ctx.Err())
-			return "", ErrContextCanceled
-		}
-		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-			logger.DefaultLogger.Warning(ctx, "Context deadline exceeded before processing: %v", ctx.Err())
-			return "", ErrContextDeadlineExceeded
-		}
-		// For any other context error
-		logger.DefaultLogger.Error(ctx, "Context error: %v", ctx.Err())
-		return "", ctx.Err()
-	}
-
-	// Validate input parameters
-	if name == "" {
-		logger.DefaultLogger.Warning(ctx, "Invalid name provided: empty string")
-		return "", ErrInvalidName
-	}
-
-	logger.DefaultLogger.Info(ctx, "Generating greeting for '%s'", name)
-
-	// Simulate some processing time to demonstrate context handling
-	select {
-	case <-ctx.Done():
-		if errors.Is(ctx.Err(), context.Canceled) {
-			logger.DefaultLogger.Warning(ctx, "Context was canceled during processing: %v", ctx.Err())
-			return "", ErrContextCanceled
-		}
-		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
-			logger.DefaultLogger.Warning(ctx, "Context deadline exceeded during processing: %v", ctx.Err())
-			return "", ErrContextDeadlineExceeded
-		}
-		// For any other context error
-		logger.DefaultLogger.Error(ctx, "Context error during processing: %v", ctx.Err())
-		return "", ctx.Err()
-	case <-time.After(100 * time.Millisecond): // Simulate a short processing time
-		// Continue processing
-	}
-
-	message := fmt.Sprintf("Howdy %s!\n", name)
-
-	logger.DefaultLogger.Info(ctx, "Generated greeting: %s", message)
-	return message, nil
+	return DefaultGreeter.Greet(ctx, name)
 }
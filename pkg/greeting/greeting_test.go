@@ -127,8 +127,9 @@ func TestGreet(t *testing.T) {
 			if tt.expectedErr != nil {
 				assert.Error(t, err)
 				if tt.name == "other context error" {
-					// For the custom error case, just check the error message
-					assert.Equal(t, tt.expectedErr.Error(), err.Error(), "Expected error %v, got %v", tt.expectedErr, err)
+					// For the custom error case, check that the original error message is preserved
+					assert.Equal(t, "Greet: "+tt.expectedErr.Error(), err.Error(), "Expected error %v, got %v", tt.expectedErr, err)
+					assert.Equal(t, CodeUnknown, CodeOf(err))
 				} else {
 					// For other cases, use errors.Is
 					assert.True(t, errors.Is(err, tt.expectedErr), "Expected error %v, got %v", tt.expectedErr, err)
@@ -0,0 +1,159 @@
+package greeting
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// contextKey is a type for context keys to avoid collisions.
+type contextKey string
+
+// LocaleKey is the key for the preferred locale in context.
+const LocaleKey contextKey = "locale"
+
+// Tag is a canonicalized BCP 47 language tag such as "en", "pt-BR" or "zh-Hant-TW".
+//
+// Tags are canonicalized by ParseTag: the language subtag is lowercased, the
+// script subtag is title-cased, the region subtag is uppercased and any other
+// subtags are lowercased. The zero value is not a valid tag.
+type Tag string
+
+// ParseTag parses and canonicalizes a BCP 47 language tag. Both "-" and "_" are
+// accepted as subtag separators, so "pt_br" and "pt-BR" yield the same Tag.
+// If the tag is malformed, the returned error wraps ErrInvalidLocale.
+//
+// # Example
+//
+//	tag, err := greeting.ParseTag("pt_br")
+//	// tag == "pt-BR"
+func ParseTag(s string) (Tag, error) {
+	subtags := strings.FieldsFunc(s, func(r rune) bool { return r == '-' || r == '_' })
+	if len(subtags) == 0 || strings.Trim(s, "-_") != s || strings.Contains(s, "--") || strings.Contains(s, "__") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidLocale, s)
+	}
+
+	language := subtags[0]
+	if len(language) < 2 || len(language) > 8 || len(language) == 4 || !isAlpha(language) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidLocale, s)
+	}
+	canonical := []string{strings.ToLower(language)}
+
+	for i, subtag := range subtags[1:] {
+		if len(subtag) > 8 || !isAlphaNum(subtag) {
+			return "", fmt.Errorf("%w: %q", ErrInvalidLocale, s)
+		}
+		switch {
+		case i == 0 && len(subtag) == 4 && isAlpha(subtag):
+			// Script, e.g. "Hant"
+			canonical = append(canonical, strings.ToUpper(subtag[:1])+strings.ToLower(subtag[1:]))
+		case i <= 1 && (len(subtag) == 2 && isAlpha(subtag) || len(subtag) == 3 && isDigit(subtag)):
+			// Region, e.g. "BR" or "419"
+			canonical = append(canonical, strings.ToUpper(subtag))
+		default:
+			canonical = append(canonical, strings.ToLower(subtag))
+		}
+	}
+
+	return Tag(strings.Join(canonical, "-")), nil
+}
+
+// Parent returns the tag with its last subtag removed, for example "pt" for
+// "pt-BR". The second return value is false if the tag has no parent.
+func (t Tag) Parent() (Tag, bool) {
+	i := strings.LastIndexByte(string(t), '-')
+	if i < 0 {
+		return "", false
+	}
+	return t[:i], true
+}
+
+// Language returns the primary language subtag, for example "pt" for "pt-BR".
+func (t Tag) Language() string {
+	language, _, _ := strings.Cut(string(t), "-")
+	return language
+}
+
+// String returns the tag as a string.
+func (t Tag) String() string {
+	return string(t)
+}
+
+// FallbackChain returns the tags to try, in order, when looking up a message for t.
+// The chain consists of t, each of its parents and finally the default locale,
+// without duplicates. For example, the chain for "pt-BR" with the default "en" is
+// ["pt-BR", "pt", "en"].
+func FallbackChain(t, defaultLocale Tag) []Tag {
+	var chain []Tag
+	for tag, ok := t, t != ""; ok; tag, ok = tag.Parent() {
+		chain = append(chain, tag)
+	}
+	for _, tag := range chain {
+		if tag == defaultLocale {
+			return chain
+		}
+	}
+	return append(chain, defaultLocale)
+}
+
+// WithLocale returns a new context with the given preferred locale.
+//
+// The locale is a BCP 47 language tag such as "pt-BR". Greetings generated with
+// the returned context are rendered using the best matching message bundle.
+// Malformed tags are ignored and the catalog's default locale is used instead.
+//
+// # Example
+//
+//	ctx = greeting.WithLocale(ctx, "pt-BR")
+//	message, _ := greeting.Greet(ctx, "João")
+//	// message: "Olá João!"
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, LocaleKey, locale)
+}
+
+// LocaleFromContext returns the locale stored in the context by WithLocale.
+// The second return value is false if no valid locale is present.
+func LocaleFromContext(ctx context.Context) (Tag, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	locale, ok := ctx.Value(LocaleKey).(string)
+	if !ok || locale == "" {
+		return "", false
+	}
+	tag, err := ParseTag(locale)
+	if err != nil {
+		return "", false
+	}
+	return tag, true
+}
+
+// isAlpha reports whether s consists only of ASCII letters.
+func isAlpha(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// isDigit reports whether s consists only of ASCII digits.
+func isDigit(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlphaNum reports whether s consists only of ASCII letters and digits.
+func isAlphaNum(s string) bool {
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package greeting
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTag(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Tag
+		valid    bool
+	}{
+		{"en", "en", true},
+		{"EN", "en", true},
+		{"pt_br", "pt-BR", true},
+		{"pt-BR", "pt-BR", true},
+		{"zh-hant-tw", "zh-Hant-TW", true},
+		{"es-419", "es-419", true},
+		{"de-CH-1996", "de-CH-1996", true},
+		{"", "", false},
+		{"e", "", false},
+		{"en-", "", false},
+		{"-en", "", false},
+		{"en--US", "", false},
+		{"en-US!", "", false},
+		{"toolongsubtag", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tag, err := ParseTag(tt.input)
+			if tt.valid {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, tag)
+			} else {
+				assert.True(t, errors.Is(err, ErrInvalidLocale), "Expected ErrInvalidLocale, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTagParent(t *testing.T) {
+	parent, ok := Tag("zh-Hant-TW").Parent()
+	assert.True(t, ok)
+	assert.Equal(t, Tag("zh-Hant"), parent)
+
+	_, ok = Tag("en").Parent()
+	assert.False(t, ok)
+
+	assert.Equal(t, "pt", Tag("pt-BR").Language())
+}
+
+func TestFallbackChain(t *testing.T) {
+	assert.Equal(t, []Tag{"pt-BR", "pt", "en"}, FallbackChain("pt-BR", "en"))
+	assert.Equal(t, []Tag{"en-GB", "en"}, FallbackChain("en-GB", "en"))
+	assert.Equal(t, []Tag{"en"}, FallbackChain("", "en"))
+}
+
+func TestLocaleFromContext(t *testing.T) {
+	_, ok := LocaleFromContext(context.Background())
+	assert.False(t, ok, "Empty context should not carry a locale")
+
+	tag, ok := LocaleFromContext(WithLocale(context.Background(), "pt_br"))
+	assert.True(t, ok)
+	assert.Equal(t, Tag("pt-BR"), tag)
+
+	_, ok = LocaleFromContext(WithLocale(context.Background(), "not a tag"))
+	assert.False(t, ok, "Malformed locales should be ignored")
+}
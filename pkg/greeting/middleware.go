@@ -0,0 +1,306 @@
+package greeting
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/bazel8_go/pkg/logger"
+)
+
+// Middleware decorates a greeting function with cross-cutting behavior such as
+// logging, caching or rate limiting. A middleware receives the next function in
+// the chain and returns a function with the same signature.
+type Middleware func(next GreeterFunc) GreeterFunc
+
+// Chain wraps fn with the given middleware. The first middleware is the outermost
+// one, so it sees the call first and the result last.
+//
+// # Parameters
+//
+// - fn: The function to decorate, typically greeting.Greet or a Greeter's Greet method.
+//
+// - mws: The middleware to apply, outermost first.
+//
+// # Return Value
+//
+// - GreeterFunc: The decorated function. It has Greet's signature, so it can be
+// assigned wherever greeting.Greet is used.
+//
+// # Example
+//
+//	greet := greeting.Chain(greeting.Greet,
+//	    greeting.NormalizeMiddleware(),
+//	    greeting.LoggingMiddleware(nil),
+//	    greeting.CacheMiddleware(greeting.CacheConfig{TTL: time.Minute}),
+//	)
+//	message, err := greet(ctx, "  John ")
+func Chain(fn GreeterFunc, mws ...Middleware) GreeterFunc {
+	for i := len(mws) - 1; i >= 0; i-- {
+		fn = mws[i](fn)
+	}
+	return fn
+}
+
+// LoggingMiddleware logs the outcome and duration of every call. Failed calls are
+// logged as errors, successful ones as information. The name is logged as a
+// logger.Sensitive value, so it is redacted. If l is nil, logger.DefaultLogger
+// is resolved on every call.
+func LoggingMiddleware(l *logger.ContextLogger) Middleware {
+	return func(next GreeterFunc) GreeterFunc {
+		return func(ctx context.Context, name string) (string, error) {
+			log := l
+			if log == nil {
+				log = logger.DefaultLogger
+			}
+
+			start := time.Now()
+			message, err := next(ctx, name)
+			if err != nil {
+				log.Error(ctx, "Greeting for '%s' failed after %v: %v", logger.Sensitive(name), time.Since(start), err)
+				return message, err
+			}
+			log.Info(ctx, "Greeting for '%s' succeeded after %v", logger.Sensitive(name), time.Since(start))
+			return message, nil
+		}
+	}
+}
+
+// TimingMiddleware measures every call and reports its duration to observe, for
+// example to record a latency histogram. If clock is nil, SystemClock is used.
+func TimingMiddleware(clock Clock, observe func(ctx context.Context, name string, elapsed time.Duration, err error)) Middleware {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return func(next GreeterFunc) GreeterFunc {
+		return func(ctx context.Context, name string) (string, error) {
+			start := clock.Now()
+			message, err := next(ctx, name)
+			observe(ctx, name, clock.Now().Sub(start), err)
+			return message, err
+		}
+	}
+}
+
+// NormalizeMiddleware trims leading and trailing whitespace from the name and
+// collapses internal runs of whitespace into a single space, so that "  John   Doe "
+// is greeted as "John Doe". Names consisting only of whitespace become empty and
+// are therefore rejected with ErrInvalidName.
+func NormalizeMiddleware() Middleware {
+	return func(next GreeterFunc) GreeterFunc {
+		return func(ctx context.Context, name string) (string, error) {
+			return next(ctx, strings.Join(strings.Fields(name), " "))
+		}
+	}
+}
+
+// CacheConfig configures CacheMiddleware.
+type CacheConfig struct {
+	// TTL is how long a greeting is cached. Zero means entries never expire.
+	TTL time.Duration
+	// MaxEntries bounds the cache size; the least recently used entry is evicted
+	// first. Zero means the cache is unbounded.
+	MaxEntries int
+	// Clock is used to expire entries. Nil means SystemClock.
+	Clock Clock
+}
+
+// cacheEntry is a cached greeting.
+type cacheEntry struct {
+	key     string
+	message string
+	expires time.Time
+}
+
+// CacheMiddleware caches successful greetings per name and locale (see WithLocale).
+// Errors are never cached. The cache is safe for concurrent use; concurrent
+// misses for the same key may each call the next function.
+func CacheMiddleware(cfg CacheConfig) Middleware {
+	if cfg.Clock == nil {
+		cfg.Clock = SystemClock
+	}
+
+	var mu sync.Mutex
+	entries := make(map[string]*list.Element)
+	lru := list.New()
+
+	return func(next GreeterFunc) GreeterFunc {
+		return func(ctx context.Context, name string) (string, error) {
+			locale, _ := LocaleFromContext(ctx)
+			key := string(locale) + "\x00" + name
+
+			mu.Lock()
+			if elem, ok := entries[key]; ok {
+				entry := elem.Value.(*cacheEntry)
+				if cfg.TTL <= 0 || cfg.Clock.Now().Before(entry.expires) {
+					lru.MoveToFront(elem)
+					mu.Unlock()
+					return entry.message, nil
+				}
+				lru.Remove(elem)
+				delete(entries, key)
+			}
+			mu.Unlock()
+
+			message, err := next(ctx, name)
+			if err != nil {
+				return message, err
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if elem, ok := entries[key]; ok {
+				lru.Remove(elem)
+			}
+			entries[key] = lru.PushFront(&cacheEntry{key: key, message: message, expires: cfg.Clock.Now().Add(cfg.TTL)})
+			if cfg.MaxEntries > 0 && lru.Len() > cfg.MaxEntries {
+				oldest := lru.Back()
+				lru.Remove(oldest)
+				delete(entries, oldest.Value.(*cacheEntry).key)
+			}
+			return message, nil
+		}
+	}
+}
+
+// RetryConfig configures RetryMiddleware.
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below one are treated as one.
+	MaxAttempts int
+	// Backoff is the wait before the first retry; it doubles after every retry,
+	// up to the largest time.Duration.
+	Backoff time.Duration
+	// Retryable reports whether a failed attempt should be retried. Nil means
+	// every error except input validation and context errors is retried.
+	Retryable func(err error) bool
+	// Clock is used to wait between attempts. Nil means SystemClock.
+	Clock Clock
+}
+
+// RetryMiddleware retries failed calls with exponential backoff. Waiting between
+// attempts stops as soon as the context is done, in which case the context error
+// is returned as an *Error matching ErrContextCanceled or ErrContextDeadlineExceeded.
+func RetryMiddleware(cfg RetryConfig) Middleware {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.Retryable == nil {
+		cfg.Retryable = isRetryable
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = SystemClock
+	}
+
+	return func(next GreeterFunc) GreeterFunc {
+		return func(ctx context.Context, name string) (string, error) {
+			backoff := cfg.Backoff
+			for attempt := 1; ; attempt++ {
+				message, err := next(ctx, name)
+				if err == nil || attempt >= cfg.MaxAttempts || !cfg.Retryable(err) {
+					return message, err
+				}
+
+				select {
+				case <-ctx.Done():
+					return "", mapContextError("RetryMiddleware", ctx.Err())
+				case <-cfg.Clock.After(backoff):
+				}
+				if backoff > math.MaxInt64/2 {
+					backoff = math.MaxInt64
+				} else {
+					backoff *= 2
+				}
+			}
+		}
+	}
+}
+
+// isRetryable is the default RetryConfig.Retryable. Invalid input and context
+// errors would fail again, so they are not retried.
+func isRetryable(err error) bool {
+	for _, permanent := range []error{
+		ErrInvalidName, ErrInvalidWinnings, ErrUnknownCurrency, ErrInvalidLocale, ErrMessageNotFound,
+		ErrContextCanceled, ErrContextDeadlineExceeded, context.Canceled, context.DeadlineExceeded,
+	} {
+		if errors.Is(err, permanent) {
+			return false
+		}
+	}
+	return true
+}
+
+// RateLimitConfig configures RateLimitMiddleware.
+type RateLimitConfig struct {
+	// Rate is the number of calls per second allowed on average.
+	Rate float64
+	// Burst is the number of calls allowed at once. Values below one are treated as one.
+	Burst int
+	// Wait makes callers wait for capacity instead of failing with ErrRateLimited.
+	Wait bool
+	// Clock is used to refill the bucket and to wait. Nil means SystemClock.
+	Clock Clock
+}
+
+// RateLimitMiddleware limits the rate of calls with a token bucket shared by all
+// callers of the returned middleware. When the bucket is empty, calls fail with
+// ErrRateLimited, or wait for a token if cfg.Wait is set. A waiting call gives
+// up as soon as its context is done.
+func RateLimitMiddleware(cfg RateLimitConfig) Middleware {
+	if cfg.Burst < 1 {
+		cfg.Burst = 1
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = SystemClock
+	}
+
+	var mu sync.Mutex
+	tokens := float64(cfg.Burst)
+	last := cfg.Clock.Now()
+
+	// reserve takes a token if one is available. Otherwise it returns how long
+	// to wait until the next token becomes available, at least a nanosecond, or
+	// zero if no token will ever become available.
+	reserve := func() (time.Duration, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		now := cfg.Clock.Now()
+		tokens = min(float64(cfg.Burst), tokens+now.Sub(last).Seconds()*cfg.Rate)
+		last = now
+
+		if tokens >= 1 {
+			tokens--
+			return 0, true
+		}
+		if cfg.Rate <= 0 {
+			return 0, false
+		}
+		// Round up, so that a wait shorter than a nanosecond is not mistaken for no wait.
+		return max(time.Duration(math.Ceil((1-tokens)/cfg.Rate*float64(time.Second))), time.Nanosecond), false
+	}
+
+	return func(next GreeterFunc) GreeterFunc {
+		return func(ctx context.Context, name string) (string, error) {
+			for {
+				wait, ok := reserve()
+				if ok {
+					return next(ctx, name)
+				}
+				if !cfg.Wait || wait == 0 {
+					return "", newError("RateLimitMiddleware", CodeRateLimited, "", nil)
+				}
+
+				select {
+				case <-ctx.Done():
+					return "", mapContextError("RateLimitMiddleware", ctx.Err())
+				case <-cfg.Clock.After(wait):
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package greeting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"math"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abitofhelp/bazel8_go/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// steppingClock is a Clock whose After channels fire immediately, advancing the
+// clock by the requested duration.
+type steppingClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *steppingClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *steppingClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func (c *steppingClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// countingGreeter returns a greeting function that counts its calls and fails
+// the first failures calls with err.
+func countingGreeter(calls *int, failures int, err error) GreeterFunc {
+	return func(ctx context.Context, name string) (string, error) {
+		*calls++
+		if *calls <= failures {
+			return "", err
+		}
+		return "Howdy " + name + "!", nil
+	}
+}
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	trace := func(label string) Middleware {
+		return func(next GreeterFunc) GreeterFunc {
+			return func(ctx context.Context, name string) (string, error) {
+				order = append(order, label)
+				return next(ctx, name+label)
+			}
+		}
+	}
+
+	var calls int
+	fn := Chain(countingGreeter(&calls, 0, nil), trace("a"), trace("b"))
+
+	message, err := fn(context.Background(), "x")
+	assert.NoError(t, err)
+	assert.Equal(t, "Howdy xab!", message)
+	assert.Equal(t, []string{"a", "b"}, order, "The first middleware should be the outermost")
+
+	assert.NotNil(t, Chain(Greet), "Chain without middleware should return the function")
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	var logOutput bytes.Buffer
+	ctxLogger := logger.NewContextLogger(log.New(&logOutput, "", 0))
+
+	var calls int
+	fn := Chain(countingGreeter(&calls, 1, errors.New("boom")), LoggingMiddleware(ctxLogger))
+
+	_, err := fn(context.Background(), "John")
+	assert.Error(t, err)
+	assert.Contains(t, logOutput.String(), "ERROR: Greeting for '[REDACTED]' failed after")
+
+	_, err = fn(context.Background(), "John")
+	assert.NoError(t, err)
+	assert.Contains(t, logOutput.String(), "INFO: Greeting for '[REDACTED]' succeeded after")
+}
+
+func TestTimingMiddleware(t *testing.T) {
+	clock := &steppingClock{now: time.Unix(0, 0)}
+	slow := GreeterFunc(func(ctx context.Context, name string) (string, error) {
+		clock.Advance(250 * time.Millisecond)
+		return name, nil
+	})
+
+	var observed time.Duration
+	fn := Chain(slow, TimingMiddleware(clock, func(ctx context.Context, name string, elapsed time.Duration, err error) {
+		observed = elapsed
+	}))
+
+	_, err := fn(context.Background(), "John")
+	assert.NoError(t, err)
+	assert.Equal(t, 250*time.Millisecond, observed)
+}
+
+func TestNormalizeMiddleware(t *testing.T) {
+	var calls int
+	fn := Chain(countingGreeter(&calls, 0, nil), NormalizeMiddleware())
+
+	message, err := fn(context.Background(), "  John \t  Doe ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Howdy John Doe!", message)
+
+	g, err := NewGreeter(WithProcessingDelay(0))
+	assert.NoError(t, err)
+	_, err = Chain(g.Greet, NormalizeMiddleware())(context.Background(), "   ")
+	assert.True(t, errors.Is(err, ErrInvalidName), "Whitespace-only names should be rejected")
+}
+
+func TestCacheMiddleware(t *testing.T) {
+	clock := &steppingClock{now: time.Unix(0, 0)}
+	var calls int
+	fn := Chain(countingGreeter(&calls, 1, errors.New("boom")), CacheMiddleware(CacheConfig{TTL: time.Minute, MaxEntries: 2, Clock: clock}))
+	ctx := context.Background()
+
+	_, err := fn(ctx, "John")
+	assert.Error(t, err)
+	_, err = fn(ctx, "John")
+	assert.NoError(t, err)
+	_, err = fn(ctx, "John")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls, "Errors should not be cached, successes should")
+
+	_, _ = fn(WithLocale(ctx, "pt"), "John")
+	assert.Equal(t, 3, calls, "Entries should be cached per locale")
+
+	clock.Advance(2 * time.Minute)
+	_, _ = fn(ctx, "John")
+	assert.Equal(t, 4, calls, "Expired entries should be refreshed")
+
+	_, _ = fn(ctx, "Alice")
+	_, _ = fn(ctx, "Bob")
+	_, _ = fn(WithLocale(ctx, "pt"), "John")
+	assert.Equal(t, 7, calls, "The least recently used entry should be evicted")
+}
+
+func TestRetryMiddleware(t *testing.T) {
+	clock := &steppingClock{now: time.Unix(0, 0)}
+	transient := errors.New("transient")
+
+	var calls int
+	fn := Chain(countingGreeter(&calls, 2, transient), RetryMiddleware(RetryConfig{MaxAttempts: 3, Backoff: time.Second, Clock: clock}))
+	message, err := fn(context.Background(), "John")
+	assert.NoError(t, err)
+	assert.Equal(t, "Howdy John!", message)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, time.Unix(3, 0), clock.Now(), "Backoff should double after every retry")
+
+	calls = 0
+	fn = Chain(countingGreeter(&calls, 5, transient), RetryMiddleware(RetryConfig{MaxAttempts: 2, Clock: clock}))
+	_, err = fn(context.Background(), "John")
+	assert.Equal(t, transient, err)
+	assert.Equal(t, 2, calls)
+
+	calls = 0
+	fn = Chain(countingGreeter(&calls, 5, ErrInvalidName), RetryMiddleware(RetryConfig{MaxAttempts: 3, Clock: clock}))
+	_, err = fn(context.Background(), "John")
+	assert.True(t, errors.Is(err, ErrInvalidName))
+	assert.Equal(t, 1, calls, "Validation errors should not be retried")
+
+	calls = 0
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	fn = Chain(countingGreeter(&calls, 5, transient), RetryMiddleware(RetryConfig{MaxAttempts: 3, Backoff: time.Hour}))
+	_, err = fn(ctx, "John")
+	assert.True(t, errors.Is(err, ErrContextCanceled), "Waiting should stop when the context is done")
+}
+
+// recordingClock is a steppingClock that records the waits it is asked for.
+type recordingClock struct {
+	steppingClock
+	waits []time.Duration
+}
+
+func (c *recordingClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	c.waits = append(c.waits, d)
+	c.mu.Unlock()
+	return c.steppingClock.After(d)
+}
+
+func TestRetryMiddlewareBackoffDoesNotOverflow(t *testing.T) {
+	clock := &recordingClock{}
+	var calls int
+	fn := Chain(countingGreeter(&calls, 5, errors.New("transient")),
+		RetryMiddleware(RetryConfig{MaxAttempts: 4, Backoff: math.MaxInt64/2 + 1, Clock: clock}))
+	_, _ = fn(context.Background(), "John")
+
+	assert.Equal(t, []time.Duration{math.MaxInt64/2 + 1, math.MaxInt64, math.MaxInt64}, clock.waits,
+		"The backoff should stop doubling at the largest duration")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	clock := &steppingClock{now: time.Unix(0, 0)}
+	var calls int
+	fn := Chain(countingGreeter(&calls, 0, nil), RateLimitMiddleware(RateLimitConfig{Rate: 1, Burst: 2, Clock: clock}))
+	ctx := context.Background()
+
+	_, err := fn(ctx, "a")
+	assert.NoError(t, err)
+	_, err = fn(ctx, "b")
+	assert.NoError(t, err)
+	_, err = fn(ctx, "Carol")
+	assert.True(t, errors.Is(err, ErrRateLimited), "The burst should be exhausted")
+	assert.NotContains(t, err.Error(), "Carol", "Rate limit errors should not expose the name")
+
+	clock.Advance(time.Second)
+	_, err = fn(ctx, "d")
+	assert.NoError(t, err, "Tokens should be refilled over time")
+
+	fn = Chain(countingGreeter(&calls, 0, nil), RateLimitMiddleware(RateLimitConfig{Rate: 2, Burst: 1, Wait: true, Clock: clock}))
+	start := clock.Now()
+	_, err = fn(ctx, "e")
+	assert.NoError(t, err)
+	_, err = fn(ctx, "f")
+	assert.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, clock.Now().Sub(start), "Waiting callers should wait for the next token")
+
+	// At high rates the wait for the next token is shorter than a nanosecond.
+	fn = Chain(countingGreeter(&calls, 0, nil), RateLimitMiddleware(RateLimitConfig{Rate: 2e9, Burst: 1, Wait: true, Clock: clock}))
+	_, err = fn(ctx, "g")
+	assert.NoError(t, err)
+	_, err = fn(ctx, "h")
+	assert.NoError(t, err, "A wait shorter than a nanosecond should be waited, not rejected")
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	blocking := Chain(countingGreeter(&calls, 0, nil), RateLimitMiddleware(RateLimitConfig{Rate: 0.001, Burst: 1, Wait: true}))
+	_, _ = blocking(canceled, "g")
+	_, err = blocking(canceled, "h")
+	assert.True(t, errors.Is(err, ErrContextCanceled))
+}
@@ -0,0 +1,272 @@
+package greeting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SymbolPosition describes where a currency symbol is placed relative to the amount.
+type SymbolPosition int
+
+const (
+	// SymbolBefore places the symbol in front of the amount (e.g. "$12.34").
+	SymbolBefore SymbolPosition = iota
+	// SymbolAfter places the symbol behind the amount (e.g. "12,34 €").
+	SymbolAfter
+)
+
+// Currency describes an ISO 4217 currency and the rules used to format amounts in it.
+//
+// # Fields
+//
+//   - Code: The ISO 4217 alphabetic code, such as "USD" or "JPY".
+//   - Exponent: The number of minor-unit digits (2 for USD, 0 for JPY, 3 for KWD).
+//   - Symbol: The symbol shown next to the amount, such as "$" or "€".
+//   - Position: Whether the symbol goes before or after the amount.
+//   - Spaced: Whether a space separates the symbol from the amount.
+//   - GroupSeparator: The separator inserted between digit groups, such as "," or ".".
+//   - DecimalSeparator: The separator between the major and minor units.
+//   - Grouping: The digit group sizes from right to left. The last size repeats,
+//     so []int{3} yields "1,234,567" and []int{3, 2} yields "12,34,567".
+type Currency struct {
+	Code             string
+	Exponent         int
+	Symbol           string
+	Position         SymbolPosition
+	Spaced           bool
+	GroupSeparator   string
+	DecimalSeparator string
+	Grouping         []int
+}
+
+// currencies holds the registered currencies keyed by their ISO 4217 code.
+var (
+	currenciesMu sync.RWMutex
+	currencies   = map[string]Currency{
+		"USD": {Code: "USD", Exponent: 2, Symbol: "$", GroupSeparator: ",", DecimalSeparator: ".", Grouping: []int{3}},
+		"CAD": {Code: "CAD", Exponent: 2, Symbol: "CA$", GroupSeparator: ",", DecimalSeparator: ".", Grouping: []int{3}},
+		"AUD": {Code: "AUD", Exponent: 2, Symbol: "A$", GroupSeparator: ",", DecimalSeparator: ".", Grouping: []int{3}},
+		"MXN": {Code: "MXN", Exponent: 2, Symbol: "MX$", GroupSeparator: ",", DecimalSeparator: ".", Grouping: []int{3}},
+		"GBP": {Code: "GBP", Exponent: 2, Symbol: "£", GroupSeparator: ",", DecimalSeparator: ".", Grouping: []int{3}},
+		"EUR": {Code: "EUR", Exponent: 2, Symbol: "€", Position: SymbolAfter, Spaced: true, GroupSeparator: ".", DecimalSeparator: ",", Grouping: []int{3}},
+		"BRL": {Code: "BRL", Exponent: 2, Symbol: "R$", Spaced: true, GroupSeparator: ".", DecimalSeparator: ",", Grouping: []int{3}},
+		"CHF": {Code: "CHF", Exponent: 2, Symbol: "CHF", Spaced: true, GroupSeparator: "'", DecimalSeparator: ".", Grouping: []int{3}},
+		"SEK": {Code: "SEK", Exponent: 2, Symbol: "kr", Position: SymbolAfter, Spaced: true, GroupSeparator: " ", DecimalSeparator: ",", Grouping: []int{3}},
+		"NOK": {Code: "NOK", Exponent: 2, Symbol: "kr", Position: SymbolAfter, Spaced: true, GroupSeparator: " ", DecimalSeparator: ",", Grouping: []int{3}},
+		"DKK": {Code: "DKK", Exponent: 2, Symbol: "kr.", Position: SymbolAfter, Spaced: true, GroupSeparator: ".", DecimalSeparator: ",", Grouping: []int{3}},
+		"INR": {Code: "INR", Exponent: 2, Symbol: "₹", GroupSeparator: ",", DecimalSeparator: ".", Grouping: []int{3, 2}},
+		"CNY": {Code: "CNY", Exponent: 2, Symbol: "CN¥", GroupSeparator: ",", DecimalSeparator: ".", Grouping: []int{3}},
+		"JPY": {Code: "JPY", Exponent: 0, Symbol: "¥", GroupSeparator: ",", DecimalSeparator: ".", Grouping: []int{3}},
+		"KRW": {Code: "KRW", Exponent: 0, Symbol: "₩", GroupSeparator: ",", DecimalSeparator: ".", Grouping: []int{3}},
+		"KWD": {Code: "KWD", Exponent: 3, Symbol: "KWD", Spaced: true, GroupSeparator: ",", DecimalSeparator: ".", Grouping: []int{3}},
+		"BHD": {Code: "BHD", Exponent: 3, Symbol: "BHD", Spaced: true, GroupSeparator: ",", DecimalSeparator: ".", Grouping: []int{3}},
+	}
+)
+
+// LookupCurrency returns the registered currency for the given ISO 4217 code.
+// The lookup is case-insensitive. If the code is not registered, the returned
+// error wraps ErrUnknownCurrency.
+//
+// # Example
+//
+//	jpy, err := greeting.LookupCurrency("JPY")
+//	if errors.Is(err, greeting.ErrUnknownCurrency) {
+//	    // Handle unsupported currency
+//	}
+func LookupCurrency(code string) (Currency, error) {
+	currenciesMu.RLock()
+	defer currenciesMu.RUnlock()
+
+	c, ok := currencies[strings.ToUpper(code)]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	c.Grouping = slices.Clone(c.Grouping)
+	return c, nil
+}
+
+// RegisterCurrency adds a currency to the registry, replacing any currency that
+// was previously registered with the same code. The code must consist of three
+// ASCII letters, the exponent must be between 0 and 4, and Grouping must not
+// contain non-positive sizes. The registry keeps its own copy of Grouping, so
+// later changes to the caller's slice do not affect it.
+func RegisterCurrency(c Currency) error {
+	if err := c.validate(); err != nil {
+		return err
+	}
+
+	c.Code = strings.ToUpper(c.Code)
+	c.Grouping = slices.Clone(c.Grouping)
+
+	currenciesMu.Lock()
+	defer currenciesMu.Unlock()
+	currencies[c.Code] = c
+	return nil
+}
+
+// maxExponent is the largest number of minor-unit digits a currency may have.
+const maxExponent = 4
+
+// validate checks the rules that RegisterCurrency enforces: the code consists of
+// three ASCII letters, the exponent is between 0 and maxExponent and Grouping
+// contains only positive sizes.
+func (c Currency) validate() error {
+	if c.Code == "" {
+		return errors.New("missing currency")
+	}
+	if len(c.Code) != 3 || strings.IndexFunc(c.Code, func(r rune) bool {
+		return (r < 'A' || r > 'Z') && (r < 'a' || r > 'z')
+	}) >= 0 {
+		return fmt.Errorf("invalid currency code %q", c.Code)
+	}
+	if c.Exponent < 0 || c.Exponent > maxExponent {
+		return fmt.Errorf("invalid exponent %d for currency %s", c.Exponent, c.Code)
+	}
+	if slices.ContainsFunc(c.Grouping, func(size int) bool { return size <= 0 }) {
+		return fmt.Errorf("invalid grouping %v for currency %s", c.Grouping, c.Code)
+	}
+	return nil
+}
+
+// Format formats an amount expressed in minor units (e.g. cents) according to the
+// currency's exponent, separators, grouping and symbol placement.
+//
+// # Example
+//
+//	usd, _ := greeting.LookupCurrency("USD")
+//	fmt.Println(usd.Format(1234567)) // $12,345.67
+//
+//	eur, _ := greeting.LookupCurrency("EUR")
+//	fmt.Println(eur.Format(1234567)) // 12.345,67 €
+//
+// Format never fails, even for a Currency that RegisterCurrency would reject:
+// an exponent outside 0 to 4 is clamped to that range and a Grouping with
+// non-positive sizes leaves the digits ungrouped.
+func (c Currency) Format(minorUnits int64) string {
+	c.Exponent = min(max(c.Exponent, 0), maxExponent)
+
+	sign := ""
+	magnitude := uint64(minorUnits)
+	if minorUnits < 0 {
+		sign = "-"
+		magnitude = -magnitude
+	}
+
+	digits := strconv.FormatUint(magnitude, 10)
+	if len(digits) <= c.Exponent {
+		digits = strings.Repeat("0", c.Exponent-len(digits)+1) + digits
+	}
+
+	major, minor := digits[:len(digits)-c.Exponent], digits[len(digits)-c.Exponent:]
+	number := groupDigits(major, c.GroupSeparator, c.Grouping)
+	if c.Exponent > 0 {
+		number += c.DecimalSeparator + minor
+	}
+
+	space := ""
+	if c.Spaced {
+		space = " "
+	}
+	if c.Position == SymbolAfter {
+		return sign + number + space + c.Symbol
+	}
+	return sign + c.Symbol + space + number
+}
+
+// groupDigits inserts sep between the digit groups described by sizes, working
+// from right to left. The last size repeats for the remaining digits. Sizes
+// that are not positive disable grouping.
+func groupDigits(digits, sep string, sizes []int) string {
+	if len(sizes) == 0 || sep == "" || slices.ContainsFunc(sizes, func(size int) bool { return size <= 0 }) {
+		return digits
+	}
+
+	var groups []string
+	for i := 0; len(digits) > 0; i++ {
+		size := sizes[min(i, len(sizes)-1)]
+		if size >= len(digits) {
+			groups = append(groups, digits)
+			break
+		}
+		groups = append(groups, digits[len(digits)-size:])
+		digits = digits[:len(digits)-size]
+	}
+
+	for i, j := 0, len(groups)-1; i < j; i, j = i+1, j-1 {
+		groups[i], groups[j] = groups[j], groups[i]
+	}
+	return strings.Join(groups, sep)
+}
+
+// Money is an amount of a specific currency, expressed in the currency's minor units.
+// For example, Money{Amount: 1234567, Currency: USD} represents $12,345.67, while
+// the same amount in JPY represents ¥1,234,567.
+type Money struct {
+	// Amount is the value in minor units (cents for USD, yen for JPY, fils for KWD).
+	Amount int64
+	// Currency describes how the amount is interpreted and formatted.
+	Currency Currency
+}
+
+// NewMoney creates a Money value for the given amount of minor units and ISO 4217
+// currency code. If the code is not registered, the returned error wraps
+// ErrUnknownCurrency.
+//
+// # Example
+//
+//	prize, err := greeting.NewMoney(1234567, "EUR")
+//	if err != nil {
+//	    log.Fatalf("Error: %v", err)
+//	}
+//	fmt.Println(prize) // 12.345,67 €
+func NewMoney(amount int64, code string) (Money, error) {
+	c, err := LookupCurrency(code)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: c}, nil
+}
+
+// String returns the amount formatted according to its currency.
+func (m Money) String() string {
+	return m.Currency.Format(m.Amount)
+}
+
+// GreetWithMoney generates a personalized greeting message that announces a prize
+// in any registered currency.
+//
+// # Parameters
+//
+//   - ctx: A context.Context that can be used to cancel the operation or set a deadline.
+//
+//   - name: The name of the person to greet. This must be a non-empty string.
+//
+//   - prize: The amount won. The amount must not be negative and its currency must
+//     satisfy the rules of RegisterCurrency; use NewMoney to build values from
+//     ISO 4217 codes.
+//
+// # Return Values
+//
+//   - string: The formatted greeting message if successful.
+//     Example: "Howdy John! You have won 12.345,67 €!"
+//
+// - error: An error if something went wrong. Possible errors include:
+//   - ErrInvalidName: If the name parameter is empty
+//   - ErrInvalidWinnings: If the amount is negative
+//   - ErrUnknownCurrency: If the prize has no currency or an invalid one
+//   - ErrContextCanceled: If the context was canceled during processing
+//   - ErrContextDeadlineExceeded: If the context deadline was exceeded
+//
+// # Example Usage
+//
+//	prize, _ := greeting.NewMoney(1234567, "JPY")
+//	message, err := greeting.GreetWithMoney(ctx, "Yuki", prize)
+//	// message: "Howdy Yuki! You have won ¥1,234,567!"
+func GreetWithMoney(ctx context.Context, name string, prize Money) (string, error) {
+	return DefaultGreeter.GreetWithMoney(ctx, name, prize)
+}
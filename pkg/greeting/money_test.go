@@ -0,0 +1,163 @@
+package greeting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrencyFormat(t *testing.T) {
+	tests := []struct {
+		code     string
+		amount   int64
+		expected string
+	}{
+		{"USD", 1234567, "$12,345.67"},
+		{"USD", 5, "$0.05"},
+		{"USD", 0, "$0.00"},
+		{"USD", -1234567, "-$12,345.67"},
+		{"EUR", 1234567, "12.345,67 €"},
+		{"JPY", 1234567, "¥1,234,567"},
+		{"JPY", 7, "¥7"},
+		{"KWD", 1234567, "KWD 1,234.567"},
+		{"KWD", 12, "KWD 0.012"},
+		{"INR", 123456789, "₹12,34,567.89"},
+		{"CHF", 100000, "CHF 1'000.00"},
+		{"SEK", 1234567, "12 345,67 kr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code+" "+tt.expected, func(t *testing.T) {
+			c, err := LookupCurrency(tt.code)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, c.Format(tt.amount))
+		})
+	}
+}
+
+func TestCurrencyFormatInvalidRules(t *testing.T) {
+	usd, _ := LookupCurrency("USD")
+
+	tests := []struct {
+		name     string
+		exponent int
+		grouping []int
+		expected string
+	}{
+		{name: "zero group size", exponent: 2, grouping: []int{0}, expected: "$1234567.89"},
+		{name: "negative group size", exponent: 2, grouping: []int{3, -2}, expected: "$1234567.89"},
+		{name: "negative exponent", exponent: -1, grouping: []int{3}, expected: "$123,456,789"},
+		{name: "large exponent", exponent: 9, grouping: []int{3}, expected: "$12,345.6789"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := usd
+			c.Exponent, c.Grouping = tt.exponent, tt.grouping
+			done := make(chan string, 1)
+			go func() { done <- c.Format(123456789) }()
+			select {
+			case result := <-done:
+				assert.Equal(t, tt.expected, result)
+			case <-time.After(5 * time.Second):
+				t.Fatal("Format should not loop forever on invalid rules")
+			}
+		})
+	}
+}
+
+func TestCurrencyGroupingIsCopied(t *testing.T) {
+	usd, err := LookupCurrency("USD")
+	assert.NoError(t, err)
+	usd.Grouping[0] = 2
+	usd, _ = LookupCurrency("USD")
+	assert.Equal(t, "$1,234,567.89", usd.Format(123456789), "Changing a looked up Grouping should not affect the registry")
+
+	grouping := []int{3}
+	assert.NoError(t, RegisterCurrency(Currency{Code: "XTG", Exponent: 2, Symbol: "G", GroupSeparator: ",", DecimalSeparator: ".", Grouping: grouping}))
+	grouping[0] = 1
+	m, err := NewMoney(123456789, "XTG")
+	assert.NoError(t, err)
+	assert.Equal(t, "G1,234,567.89", m.String(), "Changing a registered Grouping should not affect the registry")
+}
+
+func TestLookupCurrency(t *testing.T) {
+	c, err := LookupCurrency("jpy")
+	assert.NoError(t, err)
+	assert.Equal(t, "JPY", c.Code)
+	assert.Equal(t, 0, c.Exponent)
+
+	_, err = LookupCurrency("XYZ")
+	assert.True(t, errors.Is(err, ErrUnknownCurrency), "Expected ErrUnknownCurrency, got %v", err)
+}
+
+func TestRegisterCurrency(t *testing.T) {
+	assert.Error(t, RegisterCurrency(Currency{Code: "X1"}))
+	assert.Error(t, RegisterCurrency(Currency{Code: "XTS", Exponent: 5}))
+	assert.Error(t, RegisterCurrency(Currency{Code: "XTS", Grouping: []int{0}}))
+
+	err := RegisterCurrency(Currency{Code: "xts", Exponent: 1, Symbol: "T", GroupSeparator: ",", DecimalSeparator: ".", Grouping: []int{3}})
+	assert.NoError(t, err)
+
+	m, err := NewMoney(123456, "XTS")
+	assert.NoError(t, err)
+	assert.Equal(t, "T12,345.6", m.String())
+}
+
+func TestNewMoney(t *testing.T) {
+	m, err := NewMoney(1234567, "EUR")
+	assert.NoError(t, err)
+	assert.Equal(t, "12.345,67 €", m.String())
+
+	_, err = NewMoney(1, "???")
+	assert.True(t, errors.Is(err, ErrUnknownCurrency), "Expected ErrUnknownCurrency, got %v", err)
+}
+
+func TestGreetWithMoney(t *testing.T) {
+	jpy, _ := NewMoney(1234567, "JPY")
+	eur, _ := NewMoney(1234567, "EUR")
+	negative, _ := NewMoney(-1, "USD")
+	usd, _ := NewMoney(1234567, "USD")
+	zeroGroup, negativeGroup, negativeExponent := usd, usd, usd
+	zeroGroup.Currency.Grouping = []int{0}
+	negativeGroup.Currency.Grouping = []int{-3}
+	negativeExponent.Currency.Exponent = -1
+
+	tests := []struct {
+		name        string
+		input       string
+		prize       Money
+		expected    string
+		expectedErr error
+	}{
+		{name: "yen", input: "Yuki", prize: jpy, expected: "Howdy Yuki! You have won ¥1,234,567!\n"},
+		{name: "euro", input: "Marie", prize: eur, expected: "Howdy Marie! You have won 12.345,67 €!\n"},
+		{name: "negative amount", input: "John", prize: negative, expectedErr: ErrInvalidWinnings},
+		{name: "missing currency", input: "John", prize: Money{Amount: 1}, expectedErr: ErrUnknownCurrency},
+		{name: "zero group size", input: "John", prize: zeroGroup, expectedErr: ErrUnknownCurrency},
+		{name: "negative group size", input: "John", prize: negativeGroup, expectedErr: ErrUnknownCurrency},
+		{name: "negative exponent", input: "John", prize: negativeExponent, expectedErr: ErrUnknownCurrency},
+		{name: "empty name", input: "", prize: eur, expectedErr: ErrInvalidName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := GreetWithMoney(context.Background(), tt.input, tt.prize)
+
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "Expected error %v, got %v", tt.expectedErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := GreetWithMoney(canceled, "John", Money{Amount: -1})
+	assert.True(t, errors.Is(err, ErrContextCanceled), "The context should be checked before the prize, got %v", err)
+}
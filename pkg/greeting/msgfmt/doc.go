@@ -0,0 +1,52 @@
+// Package msgfmt implements a subset of ICU MessageFormat for greeting templates.
+//
+// # Overview
+//
+// Localized messages frequently need to vary with the numbers and people they
+// mention: "1 prize" but "3 prizes", or "She won" versus "They won". This package
+// compiles message patterns written in the ICU MessageFormat syntax and renders
+// them using the CLDR plural rules of the message's language. The greeting
+// catalog uses it to compile every message bundle it loads.
+//
+// # Supported Syntax
+//
+// - Simple arguments: {name}
+// - Plural arguments: {count, plural, =0 {none} one {# prize} other {# prizes}}
+// - Plural offsets: {count, plural, offset:1 one {...} other {...}}
+// - Select arguments: {gender, select, female {She} male {He} other {They}}
+// - The # placeholder, which renders the (offset) number inside plural branches
+// - Apostrophe quoting: a doubled apostrophe is a literal apostrophe and '{...}' is literal text
+//
+// Plural and select arguments must provide an "other" branch. Other ICU argument
+// types, such as number, date and selectordinal, are not supported.
+//
+// # Basic Usage
+//
+//	msg, err := msgfmt.Compile("en", "You have won {count, plural, one {# prize} other {# prizes}}")
+//	if err != nil {
+//	    log.Fatalf("Invalid template: %v", err)
+//	}
+//	s, err := msg.Format(map[string]any{"count": 3})
+//	// s: "You have won 3 prizes"
+//
+// # Error Handling
+//
+// Compile returns a *SyntaxError that records the byte offset, line and column of
+// the problem, so translators can find the offending position in a bundle:
+//
+//	msgfmt: 1:20: plural argument "count" is missing the required 'other' selector
+//
+// Format returns errors wrapping ErrMissingArgument when an argument is missing
+// and ErrInvalidArgument when a plural argument is not a number.
+//
+// # Plural Rules
+//
+// Cardinal plural rules are built in for Arabic, Chinese, Dutch, English, French,
+// German, Italian, Japanese, Korean, Polish, Portuguese, Russian, Spanish, Swedish
+// and Ukrainian. Rules for other languages can be added with RegisterPluralRule;
+// languages without a rule use the "other" category for every number.
+//
+// # Thread Safety
+//
+// Compiled messages are immutable and safe for concurrent use by multiple goroutines.
+package msgfmt
@@ -0,0 +1,514 @@
+// Package msgfmt implements a subset of ICU MessageFormat for greeting templates.
+// See doc.go for detailed package documentation.
+package msgfmt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Error definitions for the msgfmt package.
+var (
+	// ErrMissingArgument is returned when a message references an argument that was not provided.
+	ErrMissingArgument = errors.New("missing argument")
+
+	// ErrInvalidArgument is returned when an argument cannot be used by its placeholder,
+	// such as a non-numeric value passed to a plural argument.
+	ErrInvalidArgument = errors.New("invalid argument")
+)
+
+// SyntaxError describes a problem found while compiling a message pattern.
+// It records the position of the offending input so that translators can
+// locate the error in the bundle.
+type SyntaxError struct {
+	// Offset is the byte offset of the error in the pattern.
+	Offset int
+	// Line is the 1-based line number of the error.
+	Line int
+	// Column is the 1-based column, in runes, of the error.
+	Column int
+	// Msg describes the problem.
+	Msg string
+}
+
+// Error returns the error message including its line and column.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("msgfmt: %d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Message is a compiled message pattern. A Message is immutable and safe for
+// concurrent use by multiple goroutines.
+type Message struct {
+	lang  string
+	nodes []node
+}
+
+// node is a compiled element of a message: literal text, an argument, the
+// plural number placeholder (#), or a plural or select construct.
+type node interface {
+	format(sb *strings.Builder, m *Message, args map[string]any, number *pluralNumber) error
+}
+
+// Compile parses a message pattern for the given language.
+//
+// # Parameters
+//
+//   - lang: The language whose plural rules are used, as a BCP 47 tag or just the
+//     primary language subtag (e.g. "pt-BR" or "pt").
+//
+//   - pattern: The message pattern, for example
+//     "You have won {count, plural, one {# prize} other {# prizes}}".
+//
+// # Return Values
+//
+//   - *Message: The compiled message if the pattern is valid.
+//
+//   - error: A *SyntaxError pointing to the offending position if the pattern is invalid.
+//
+// # Example
+//
+//	msg, err := msgfmt.Compile("en", "{count, plural, one {# prize} other {# prizes}}")
+//	if err != nil {
+//	    log.Fatalf("Error: %v", err)
+//	}
+//	s, _ := msg.Format(map[string]any{"count": 3})
+//	// s: "3 prizes"
+func Compile(lang, pattern string) (*Message, error) {
+	p := &parser{src: pattern}
+	nodes, err := p.parseMessage(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.src) {
+		return nil, p.errorf(p.pos, "unexpected '}'")
+	}
+	return &Message{lang: lang, nodes: nodes}, nil
+}
+
+// MustCompile is like Compile but panics if the pattern cannot be compiled.
+// It simplifies the initialization of global variables holding messages.
+func MustCompile(lang, pattern string) *Message {
+	m, err := Compile(lang, pattern)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+// Format renders the message with the given arguments.
+//
+// Arguments are formatted with fmt.Sprint, except numbers used by plural
+// constructs, which are formatted without exponent notation. If an argument is
+// missing, the returned error wraps ErrMissingArgument. If a plural argument is
+// not a number, the returned error wraps ErrInvalidArgument.
+func (m *Message) Format(args map[string]any) (string, error) {
+	var sb strings.Builder
+	if err := formatNodes(&sb, m, m.nodes, args, nil); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// formatNodes renders a sequence of nodes into sb.
+func formatNodes(sb *strings.Builder, m *Message, nodes []node, args map[string]any, number *pluralNumber) error {
+	for _, n := range nodes {
+		if err := n.format(sb, m, args, number); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// textNode is literal text.
+type textNode string
+
+func (t textNode) format(sb *strings.Builder, _ *Message, _ map[string]any, _ *pluralNumber) error {
+	sb.WriteString(string(t))
+	return nil
+}
+
+// argNode is a simple {name} placeholder.
+type argNode string
+
+func (a argNode) format(sb *strings.Builder, _ *Message, args map[string]any, _ *pluralNumber) error {
+	value, ok := args[string(a)]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrMissingArgument, string(a))
+	}
+	fmt.Fprint(sb, value)
+	return nil
+}
+
+// numberNode is the # placeholder inside a plural branch.
+type numberNode struct{}
+
+func (numberNode) format(sb *strings.Builder, _ *Message, _ map[string]any, number *pluralNumber) error {
+	sb.WriteString(number.text)
+	return nil
+}
+
+// pluralNode is a {name, plural, ...} construct.
+type pluralNode struct {
+	arg      string
+	offset   int64
+	exact    map[string][]node
+	branches map[PluralCategory][]node
+}
+
+func (p *pluralNode) format(sb *strings.Builder, m *Message, args map[string]any, _ *pluralNumber) error {
+	value, ok := args[p.arg]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrMissingArgument, p.arg)
+	}
+	ops, err := NewOperands(value)
+	if err != nil {
+		return fmt.Errorf("%w: %q: %v", ErrInvalidArgument, p.arg, err)
+	}
+
+	// Explicit values are matched against the argument before the offset is applied.
+	if branch, ok := p.exact[ops.text]; ok {
+		return formatNodes(sb, m, branch, args, &pluralNumber{text: ops.text})
+	}
+
+	if p.offset != 0 {
+		ops, err = ops.subtract(p.offset)
+		if err != nil {
+			return fmt.Errorf("%w: %q: %v", ErrInvalidArgument, p.arg, err)
+		}
+	}
+
+	branch, ok := p.branches[Cardinal(m.lang, ops)]
+	if !ok {
+		branch = p.branches[Other]
+	}
+	return formatNodes(sb, m, branch, args, &pluralNumber{text: ops.text})
+}
+
+// selectNode is a {name, select, ...} construct.
+type selectNode struct {
+	arg      string
+	branches map[string][]node
+}
+
+func (s *selectNode) format(sb *strings.Builder, m *Message, args map[string]any, number *pluralNumber) error {
+	value, ok := args[s.arg]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrMissingArgument, s.arg)
+	}
+	branch, ok := s.branches[fmt.Sprint(value)]
+	if !ok {
+		branch = s.branches["other"]
+	}
+	return formatNodes(sb, m, branch, args, number)
+}
+
+// pluralNumber carries the formatted number that # renders inside a plural branch.
+type pluralNumber struct {
+	text string
+}
+
+// parser is a recursive-descent parser for message patterns.
+type parser struct {
+	src string
+	pos int
+}
+
+// errorf returns a *SyntaxError for the given byte offset.
+func (p *parser) errorf(offset int, format string, args ...any) error {
+	prefix := p.src[:offset]
+	line := strings.Count(prefix, "\n") + 1
+	column := utf8.RuneCountInString(prefix[strings.LastIndexByte(prefix, '\n')+1:]) + 1
+	return &SyntaxError{Offset: offset, Line: line, Column: column, Msg: fmt.Sprintf(format, args...)}
+}
+
+// parseMessage parses text and placeholders until the end of input or an
+// unmatched '}'. Inside plural branches (pluralDepth > 0), '#' renders the
+// plural number.
+func (p *parser) parseMessage(pluralDepth int) ([]node, error) {
+	var nodes []node
+	var text strings.Builder
+
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, textNode(text.String()))
+			text.Reset()
+		}
+	}
+
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '}':
+			flush()
+			return nodes, nil
+		case c == '{':
+			flush()
+			n, err := p.parsePlaceholder(pluralDepth)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, n)
+		case c == '#' && pluralDepth > 0:
+			flush()
+			nodes = append(nodes, numberNode{})
+			p.pos++
+		case c == '\'':
+			if err := p.parseQuoted(&text, pluralDepth > 0); err != nil {
+				return nil, err
+			}
+		default:
+			text.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	flush()
+	return nodes, nil
+}
+
+// parseQuoted handles apostrophes: a doubled apostrophe is literal, and an
+// apostrophe before a syntax character starts a quoted literal that runs until
+// the next single apostrophe. Any other apostrophe is literal.
+func (p *parser) parseQuoted(text *strings.Builder, inPlural bool) error {
+	start := p.pos
+	p.pos++
+	if p.pos >= len(p.src) {
+		text.WriteByte('\'')
+		return nil
+	}
+
+	next := p.src[p.pos]
+	if next == '\'' {
+		text.WriteByte('\'')
+		p.pos++
+		return nil
+	}
+	if next != '{' && next != '}' && !(next == '#' && inPlural) {
+		text.WriteByte('\'')
+		return nil
+	}
+
+	for p.pos < len(p.src) {
+		if p.src[p.pos] == '\'' {
+			if p.pos+1 < len(p.src) && p.src[p.pos+1] == '\'' {
+				text.WriteByte('\'')
+				p.pos += 2
+				continue
+			}
+			p.pos++
+			return nil
+		}
+		text.WriteByte(p.src[p.pos])
+		p.pos++
+	}
+	return p.errorf(start, "unterminated quoted literal")
+}
+
+// parsePlaceholder parses a placeholder starting at '{'.
+func (p *parser) parsePlaceholder(pluralDepth int) (node, error) {
+	start := p.pos
+	p.pos++ // consume '{'
+
+	p.skipSpace()
+	name := p.parseIdentifier()
+	if name == "" {
+		return nil, p.errorf(p.pos, "expected argument name")
+	}
+	p.skipSpace()
+
+	if p.consume('}') {
+		return argNode(name), nil
+	}
+	if !p.consume(',') {
+		return nil, p.expected(start, "',' or '}'")
+	}
+
+	p.skipSpace()
+	typePos := p.pos
+	argType := p.parseIdentifier()
+	p.skipSpace()
+
+	var n node
+	var err error
+	switch argType {
+	case "plural":
+		if !p.consume(',') {
+			return nil, p.expected(start, "','")
+		}
+		n, err = p.parsePlural(name, pluralDepth)
+	case "select":
+		if !p.consume(',') {
+			return nil, p.expected(start, "','")
+		}
+		n, err = p.parseSelect(name, pluralDepth)
+	case "":
+		return nil, p.errorf(typePos, "expected argument type")
+	default:
+		return nil, p.errorf(typePos, "unsupported argument type %q", argType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if !p.consume('}') {
+		return nil, p.expected(start, "'}'")
+	}
+	return n, nil
+}
+
+// parsePlural parses the body of a plural construct after "plural,".
+func (p *parser) parsePlural(arg string, pluralDepth int) (node, error) {
+	n := &pluralNode{arg: arg, exact: map[string][]node{}, branches: map[PluralCategory][]node{}}
+
+	p.skipSpace()
+	if strings.HasPrefix(p.src[p.pos:], "offset:") {
+		p.pos += len("offset:")
+		p.skipSpace()
+		offsetPos := p.pos
+		digits := p.parseWhile(func(r rune) bool { return r >= '0' && r <= '9' })
+		offset, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil {
+			return nil, p.errorf(offsetPos, "invalid plural offset")
+		}
+		n.offset = offset
+	}
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] == '}' {
+			break
+		}
+
+		selectorPos := p.pos
+		if p.consume('=') {
+			value := p.parseWhile(func(r rune) bool { return r >= '0' && r <= '9' || r == '.' || r == '-' })
+			ops, err := NewOperands(value)
+			if value == "" || err != nil {
+				return nil, p.errorf(selectorPos, "invalid explicit value %q", "="+value)
+			}
+			if _, dup := n.exact[ops.text]; dup {
+				return nil, p.errorf(selectorPos, "duplicate selector %q", "="+value)
+			}
+			branch, err := p.parseBranch(pluralDepth + 1)
+			if err != nil {
+				return nil, err
+			}
+			n.exact[ops.text] = branch
+			continue
+		}
+
+		keyword := PluralCategory(p.parseIdentifier())
+		if !keyword.valid() {
+			return nil, p.errorf(selectorPos, "invalid plural category %q", keyword)
+		}
+		if _, dup := n.branches[keyword]; dup {
+			return nil, p.errorf(selectorPos, "duplicate selector %q", keyword)
+		}
+		branch, err := p.parseBranch(pluralDepth + 1)
+		if err != nil {
+			return nil, err
+		}
+		n.branches[keyword] = branch
+	}
+
+	if _, ok := n.branches[Other]; !ok {
+		return nil, p.errorf(p.pos, "plural argument %q is missing the required 'other' selector", arg)
+	}
+	return n, nil
+}
+
+// parseSelect parses the body of a select construct after "select,".
+func (p *parser) parseSelect(arg string, pluralDepth int) (node, error) {
+	n := &selectNode{arg: arg, branches: map[string][]node{}}
+
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] == '}' {
+			break
+		}
+
+		selectorPos := p.pos
+		keyword := p.parseIdentifier()
+		if keyword == "" {
+			return nil, p.errorf(selectorPos, "expected select keyword")
+		}
+		if _, dup := n.branches[keyword]; dup {
+			return nil, p.errorf(selectorPos, "duplicate selector %q", keyword)
+		}
+		branch, err := p.parseBranch(pluralDepth)
+		if err != nil {
+			return nil, err
+		}
+		n.branches[keyword] = branch
+	}
+
+	if _, ok := n.branches["other"]; !ok {
+		return nil, p.errorf(p.pos, "select argument %q is missing the required 'other' selector", arg)
+	}
+	return n, nil
+}
+
+// parseBranch parses a "{...}" sub-message following a selector.
+func (p *parser) parseBranch(pluralDepth int) ([]node, error) {
+	p.skipSpace()
+	start := p.pos
+	if !p.consume('{') {
+		return nil, p.errorf(p.pos, "expected '{' after selector")
+	}
+	nodes, err := p.parseMessage(pluralDepth)
+	if err != nil {
+		return nil, err
+	}
+	if !p.consume('}') {
+		return nil, p.errorf(start, "unterminated sub-message")
+	}
+	return nodes, nil
+}
+
+// expected returns a syntax error describing what was expected at the current
+// position, or an unterminated placeholder error at start if input ended.
+func (p *parser) expected(start int, what string) error {
+	if p.pos >= len(p.src) {
+		return p.errorf(start, "unterminated placeholder")
+	}
+	return p.errorf(p.pos, "expected %s, found %q", what, p.src[p.pos])
+}
+
+// consume advances past c if it is the next byte.
+func (p *parser) consume(c byte) bool {
+	if p.pos < len(p.src) && p.src[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// skipSpace advances past any whitespace.
+func (p *parser) skipSpace() {
+	p.parseWhile(unicode.IsSpace)
+}
+
+// parseIdentifier parses an argument name or keyword.
+func (p *parser) parseIdentifier() string {
+	return p.parseWhile(func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	})
+}
+
+// parseWhile consumes and returns the longest prefix whose runes satisfy f.
+func (p *parser) parseWhile(f func(rune) bool) string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if !f(r) {
+			break
+		}
+		p.pos += size
+	}
+	return p.src[start:p.pos]
+}
@@ -0,0 +1,163 @@
+package msgfmt
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		lang     string
+		pattern  string
+		args     map[string]any
+		expected string
+	}{
+		{name: "plain text", lang: "en", pattern: "Howdy!", expected: "Howdy!"},
+		{name: "simple argument", lang: "en", pattern: "Howdy {name}!", args: map[string]any{"name": "John"}, expected: "Howdy John!"},
+		{name: "argument with spaces", lang: "en", pattern: "Howdy { name }!", args: map[string]any{"name": "John"}, expected: "Howdy John!"},
+		{
+			name:     "plural one",
+			lang:     "en",
+			pattern:  "You have won {count, plural, one {# prize} other {# prizes}}",
+			args:     map[string]any{"count": 1},
+			expected: "You have won 1 prize",
+		},
+		{
+			name:     "plural other",
+			lang:     "en",
+			pattern:  "You have won {count, plural, one {# prize} other {# prizes}}",
+			args:     map[string]any{"count": 3},
+			expected: "You have won 3 prizes",
+		},
+		{
+			name:     "plural exact match",
+			lang:     "en",
+			pattern:  "{count, plural, =0 {No prizes} one {# prize} other {# prizes}}",
+			args:     map[string]any{"count": 0},
+			expected: "No prizes",
+		},
+		{
+			name:     "plural visible fraction digits",
+			lang:     "en",
+			pattern:  "{count, plural, one {# point} other {# points}}",
+			args:     map[string]any{"count": "1.0"},
+			expected: "1.0 points",
+		},
+		{
+			name:     "plural offset",
+			lang:     "en",
+			pattern:  "{count, plural, offset:1 =0 {Nobody} =1 {{name}} one {{name} and # other} other {{name} and # others}}",
+			args:     map[string]any{"count": 3, "name": "Ann"},
+			expected: "Ann and 2 others",
+		},
+		{
+			name:     "russian few",
+			lang:     "ru",
+			pattern:  "{count, plural, one {# приз} few {# приза} many {# призов} other {# приза}}",
+			args:     map[string]any{"count": 3},
+			expected: "3 приза",
+		},
+		{
+			name:     "missing category falls back to other",
+			lang:     "ru",
+			pattern:  "{count, plural, one {# приз} other {# призов}}",
+			args:     map[string]any{"count": 5},
+			expected: "5 призов",
+		},
+		{
+			name:     "select",
+			lang:     "en",
+			pattern:  "{gender, select, female {She} male {He} other {They}} won",
+			args:     map[string]any{"gender": "female"},
+			expected: "She won",
+		},
+		{
+			name:     "select other",
+			lang:     "en",
+			pattern:  "{gender, select, female {She} male {He} other {They}} won",
+			args:     map[string]any{"gender": "unknown"},
+			expected: "They won",
+		},
+		{
+			name:     "nested select in plural keeps number",
+			lang:     "en",
+			pattern:  "{count, plural, one {{gender, select, female {her} other {their}} # prize} other {# prizes}}",
+			args:     map[string]any{"count": 1, "gender": "female"},
+			expected: "her 1 prize",
+		},
+		{name: "hash outside plural is literal", lang: "en", pattern: "Ticket #{id}", args: map[string]any{"id": 7}, expected: "Ticket #7"},
+		{name: "escaped apostrophe", lang: "en", pattern: "It''s {name}", args: map[string]any{"name": "Ann"}, expected: "It's Ann"},
+		{name: "plain apostrophe", lang: "en", pattern: "It's {name}", args: map[string]any{"name": "Ann"}, expected: "It's Ann"},
+		{name: "quoted braces", lang: "en", pattern: "Use '{name}' literally", expected: "Use {name} literally"},
+		{
+			name:     "quoted hash in plural",
+			lang:     "en",
+			pattern:  "{count, plural, other {'#' is #}}",
+			args:     map[string]any{"count": 2},
+			expected: "# is 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := Compile(tt.lang, tt.pattern)
+			assert.NoError(t, err)
+
+			result, err := msg.Format(tt.args)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	msg := MustCompile("en", "{name} has {count, plural, other {# prizes}}")
+
+	_, err := msg.Format(map[string]any{"count": 1})
+	assert.True(t, errors.Is(err, ErrMissingArgument), "Expected ErrMissingArgument, got %v", err)
+
+	_, err = msg.Format(map[string]any{"name": "Ann"})
+	assert.True(t, errors.Is(err, ErrMissingArgument), "Expected ErrMissingArgument, got %v", err)
+
+	_, err = msg.Format(map[string]any{"name": "Ann", "count": "many"})
+	assert.True(t, errors.Is(err, ErrInvalidArgument), "Expected ErrInvalidArgument, got %v", err)
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		line    int
+		column  int
+	}{
+		{name: "unterminated placeholder", pattern: "Howdy {name", line: 1, column: 7},
+		{name: "empty placeholder", pattern: "Howdy {}", line: 1, column: 8},
+		{name: "unmatched closing brace", pattern: "Howdy }", line: 1, column: 7},
+		{name: "unsupported type", pattern: "{n, date}", line: 1, column: 5},
+		{name: "missing other", pattern: "{n, plural, one {x}}", line: 1, column: 20},
+		{name: "invalid category", pattern: "{n, plural, lots {x} other {y}}", line: 1, column: 13},
+		{name: "duplicate selector", pattern: "{n, select, a {x} a {y} other {z}}", line: 1, column: 19},
+		{name: "missing branch", pattern: "{n, select, other}", line: 1, column: 18},
+		{name: "unterminated quote", pattern: "'{oops", line: 1, column: 1},
+		{name: "position on later line", pattern: "Line one\nnaïve {n, plural, one {x}}", line: 2, column: 26},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile("en", tt.pattern)
+
+			var syntaxErr *SyntaxError
+			if assert.True(t, errors.As(err, &syntaxErr), "Expected *SyntaxError, got %v", err) {
+				assert.Equal(t, tt.line, syntaxErr.Line, "line: %v", err)
+				assert.Equal(t, tt.column, syntaxErr.Column, "column: %v", err)
+			}
+		})
+	}
+}
+
+func TestMustCompilePanics(t *testing.T) {
+	assert.Panics(t, func() { MustCompile("en", "{") })
+}
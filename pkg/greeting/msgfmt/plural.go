@@ -0,0 +1,290 @@
+package msgfmt
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PluralCategory is a CLDR plural category.
+type PluralCategory string
+
+// CLDR plural categories. Every language uses Other; most use only a subset of the rest.
+const (
+	Zero  PluralCategory = "zero"
+	One   PluralCategory = "one"
+	Two   PluralCategory = "two"
+	Few   PluralCategory = "few"
+	Many  PluralCategory = "many"
+	Other PluralCategory = "other"
+)
+
+// valid reports whether c is one of the CLDR plural categories.
+func (c PluralCategory) valid() bool {
+	switch c {
+	case Zero, One, Two, Few, Many, Other:
+		return true
+	}
+	return false
+}
+
+// Operands are the CLDR plural operands of a number.
+//
+// See https://unicode.org/reports/tr35/tr35-numbers.html#Operands for details.
+type Operands struct {
+	// N is the absolute value of the number.
+	N float64
+	// I is the integer digits of N.
+	I uint64
+	// V is the number of visible fraction digits, with trailing zeros.
+	V int
+	// W is the number of visible fraction digits, without trailing zeros.
+	W int
+	// F is the visible fraction digits, with trailing zeros, as an integer.
+	F uint64
+	// T is the visible fraction digits, without trailing zeros, as an integer.
+	T uint64
+
+	// text is the number as written, used for # and explicit =N selectors.
+	text string
+}
+
+// NewOperands computes the plural operands of a number. The value may be any
+// integer or floating-point type, or a string holding a decimal number such as
+// "1.50" (which, unlike the float 1.5, has two visible fraction digits).
+func NewOperands(value any) (Operands, error) {
+	var text string
+	switch v := value.(type) {
+	case int:
+		text = strconv.FormatInt(int64(v), 10)
+	case int8:
+		text = strconv.FormatInt(int64(v), 10)
+	case int16:
+		text = strconv.FormatInt(int64(v), 10)
+	case int32:
+		text = strconv.FormatInt(int64(v), 10)
+	case int64:
+		text = strconv.FormatInt(v, 10)
+	case uint:
+		text = strconv.FormatUint(uint64(v), 10)
+	case uint8:
+		text = strconv.FormatUint(uint64(v), 10)
+	case uint16:
+		text = strconv.FormatUint(uint64(v), 10)
+	case uint32:
+		text = strconv.FormatUint(uint64(v), 10)
+	case uint64:
+		text = strconv.FormatUint(v, 10)
+	case float32:
+		text = strconv.FormatFloat(float64(v), 'f', -1, 32)
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		text = v
+	default:
+		return Operands{}, fmt.Errorf("%T is not a number", value)
+	}
+
+	digits := strings.TrimPrefix(text, "-")
+	intPart, fracPart, hasFrac := strings.Cut(digits, ".")
+	if intPart == "" || !isDigits(intPart) || hasFrac && (fracPart == "" || !isDigits(fracPart)) {
+		return Operands{}, fmt.Errorf("%q is not a decimal number", text)
+	}
+
+	n, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		return Operands{}, fmt.Errorf("%q is not a decimal number", text)
+	}
+	i, err := strconv.ParseUint(intPart, 10, 64)
+	if err != nil {
+		return Operands{}, fmt.Errorf("%q is out of range", text)
+	}
+
+	ops := Operands{N: n, I: i, V: len(fracPart), text: text}
+	if fracPart != "" {
+		trimmed := strings.TrimRight(fracPart, "0")
+		ops.W = len(trimmed)
+		// Fraction digits beyond 18 cannot change a plural category; truncate them to avoid overflow.
+		ops.F, _ = strconv.ParseUint(fracPart[:min(len(fracPart), 18)], 10, 64)
+		if trimmed != "" {
+			ops.T, _ = strconv.ParseUint(trimmed[:min(len(trimmed), 18)], 10, 64)
+		}
+	}
+	return ops, nil
+}
+
+// subtract returns the operands of the number minus offset, keeping the number
+// of visible fraction digits.
+func (o Operands) subtract(offset int64) (Operands, error) {
+	n := o.N
+	if strings.HasPrefix(o.text, "-") {
+		n = -n
+	}
+	return NewOperands(strconv.FormatFloat(n-float64(offset), 'f', o.V, 64))
+}
+
+// isInteger reports whether the number has no fraction digits, visible or not.
+func (o Operands) isInteger() bool {
+	return o.N == math.Trunc(o.N)
+}
+
+// PluralRule selects the plural category for a number.
+type PluralRule func(o Operands) PluralCategory
+
+// pluralRules holds the registered cardinal plural rules keyed by language.
+var (
+	pluralRulesMu sync.RWMutex
+	pluralRules   = map[string]PluralRule{
+		"en": ruleOneIntegerOnly,
+		"de": ruleOneIntegerOnly,
+		"nl": ruleOneIntegerOnly,
+		"sv": ruleOneIntegerOnly,
+		"it": ruleOneIntegerOnly,
+		"es": ruleSpanish,
+		"pt": rulePortuguese,
+		"fr": ruleFrench,
+		"ja": ruleOtherOnly,
+		"zh": ruleOtherOnly,
+		"ko": ruleOtherOnly,
+		"ru": ruleEastSlavic,
+		"uk": ruleEastSlavic,
+		"pl": rulePolish,
+		"ar": ruleArabic,
+	}
+)
+
+// RegisterPluralRule registers the cardinal plural rule for a language,
+// replacing any rule previously registered for it. The language is the
+// primary language subtag, such as "cy".
+func RegisterPluralRule(lang string, rule PluralRule) {
+	pluralRulesMu.Lock()
+	defer pluralRulesMu.Unlock()
+	pluralRules[strings.ToLower(lang)] = rule
+}
+
+// Cardinal returns the cardinal plural category of a number in the given language.
+// The language may be a full BCP 47 tag; only its primary subtag is used.
+// Languages without a registered rule use Other for every number.
+//
+// # Example
+//
+//	ops, _ := msgfmt.NewOperands(3)
+//	msgfmt.Cardinal("ru", ops) // Few
+func Cardinal(lang string, o Operands) PluralCategory {
+	language, _, _ := strings.Cut(strings.ToLower(lang), "-")
+
+	pluralRulesMu.RLock()
+	rule, ok := pluralRules[language]
+	pluralRulesMu.RUnlock()
+
+	if !ok {
+		return Other
+	}
+	return rule(o)
+}
+
+// ruleOtherOnly is used by languages without plural inflection, such as Japanese.
+func ruleOtherOnly(Operands) PluralCategory {
+	return Other
+}
+
+// ruleOneIntegerOnly is used by English and German: one: i = 1 and v = 0.
+func ruleOneIntegerOnly(o Operands) PluralCategory {
+	if o.I == 1 && o.V == 0 {
+		return One
+	}
+	return Other
+}
+
+// ruleSpanish: one: n = 1; many: e = 0 and i != 0 and i % 1000000 = 0 and v = 0.
+func ruleSpanish(o Operands) PluralCategory {
+	if o.N == 1 {
+		return One
+	}
+	if o.I != 0 && o.I%1000000 == 0 && o.V == 0 {
+		return Many
+	}
+	return Other
+}
+
+// rulePortuguese: one: i = 0..1; many: e = 0 and i != 0 and i % 1000000 = 0 and v = 0.
+func rulePortuguese(o Operands) PluralCategory {
+	if o.I <= 1 {
+		return One
+	}
+	if o.I%1000000 == 0 && o.V == 0 {
+		return Many
+	}
+	return Other
+}
+
+// ruleFrench: one: i = 0,1; many: e = 0 and i != 0 and i % 1000000 = 0 and v = 0.
+func ruleFrench(o Operands) PluralCategory {
+	return rulePortuguese(o)
+}
+
+// ruleEastSlavic is used by Russian and Ukrainian.
+func ruleEastSlavic(o Operands) PluralCategory {
+	if o.V != 0 {
+		return Other
+	}
+	mod10, mod100 := o.I%10, o.I%100
+	switch {
+	case mod10 == 1 && mod100 != 11:
+		return One
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return Few
+	default:
+		return Many
+	}
+}
+
+// rulePolish is used by Polish.
+func rulePolish(o Operands) PluralCategory {
+	if o.V != 0 {
+		return Other
+	}
+	mod10, mod100 := o.I%10, o.I%100
+	switch {
+	case o.I == 1:
+		return One
+	case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+		return Few
+	default:
+		return Many
+	}
+}
+
+// ruleArabic is used by Arabic.
+func ruleArabic(o Operands) PluralCategory {
+	if !o.isInteger() {
+		return Other
+	}
+	mod100 := uint64(o.N) % 100
+	switch {
+	case o.N == 0:
+		return Zero
+	case o.N == 1:
+		return One
+	case o.N == 2:
+		return Two
+	case mod100 >= 3 && mod100 <= 10:
+		return Few
+	case mod100 >= 11:
+		return Many
+	default:
+		return Other
+	}
+}
+
+// isDigits reports whether s consists only of ASCII digits.
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package msgfmt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOperands(t *testing.T) {
+	ops, err := NewOperands("1.50")
+	assert.NoError(t, err)
+	assert.Equal(t, Operands{N: 1.5, I: 1, V: 2, W: 1, F: 50, T: 5, text: "1.50"}, ops)
+
+	ops, err = NewOperands(-3)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), ops.I)
+	assert.Equal(t, 3.0, ops.N)
+
+	ops, err = NewOperands(2.5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ops.V)
+
+	for _, invalid := range []any{"abc", "1.", ".5", "1e3", struct{}{}} {
+		_, err = NewOperands(invalid)
+		assert.Error(t, err, "%v should not be a valid number", invalid)
+	}
+}
+
+func TestCardinal(t *testing.T) {
+	tests := []struct {
+		lang     string
+		value    any
+		expected PluralCategory
+	}{
+		{"en", 1, One},
+		{"en", 0, Other},
+		{"en", 2, Other},
+		{"en", "1.0", Other},
+		{"en-GB", 1, One},
+		{"de", 1, One},
+		{"fr", 0, One},
+		{"fr", 1, One},
+		{"fr", 2, Other},
+		{"fr", 1000000, Many},
+		{"pt", 0, One},
+		{"pt-BR", 1, One},
+		{"es", 1, One},
+		{"es", 0, Other},
+		{"ja", 1, Other},
+		{"ru", 1, One},
+		{"ru", 11, Many},
+		{"ru", 22, Few},
+		{"ru", 25, Many},
+		{"ru", "1.5", Other},
+		{"pl", 1, One},
+		{"pl", 3, Few},
+		{"pl", 21, Many},
+		{"ar", 0, Zero},
+		{"ar", 2, Two},
+		{"ar", 105, Few},
+		{"ar", 111, Many},
+		{"ar", 100, Other},
+		{"xx", 1, Other},
+	}
+
+	for _, tt := range tests {
+		ops, err := NewOperands(tt.value)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.expected, Cardinal(tt.lang, ops), "%s %v", tt.lang, tt.value)
+	}
+}
+
+func TestRegisterPluralRule(t *testing.T) {
+	RegisterPluralRule("XX", func(o Operands) PluralCategory {
+		if o.I == 2 {
+			return Two
+		}
+		return Other
+	})
+
+	ops, _ := NewOperands(2)
+	assert.Equal(t, Two, Cardinal("xx-YY", ops))
+}
@@ -0,0 +1,216 @@
+package greeting
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// StreamOptions configures GreetStream and GreetChan.
+type StreamOptions struct {
+	// Concurrency is the maximum number of names greeted at the same time.
+	// Zero or negative values mean DefaultBatchConcurrency.
+	Concurrency int
+	// Greeter generates the individual greetings. Nil means DefaultGreeter.
+	Greeter Greeter
+	// Ordered makes the stream yield results in input order. Otherwise results
+	// are yielded as soon as they complete.
+	Ordered bool
+	// Buffer is the number of completed results that may wait for the consumer
+	// before the stream stops reading names. Zero or negative values mean
+	// Concurrency.
+	Buffer int
+}
+
+// GreetStream greets the names produced by a sequence and yields the results as
+// they become available.
+//
+// # Function Purpose
+//
+// This function is the streaming counterpart of GreetBatch for inputs that are
+// too large to hold in memory or that arrive over time. Names are read lazily:
+// at most Concurrency+Buffer names are in flight or waiting for the consumer, so
+// a slow consumer slows down the reading of names (backpressure).
+//
+// # Parameters
+//
+//   - ctx: A context.Context that can be used to cancel the stream or set a deadline.
+//
+//   - names: The names to greet. The sequence is consumed by a separate goroutine.
+//
+//   - opts: The stream configuration. The zero value greets with DefaultGreeter
+//     using DefaultBatchConcurrency workers and yields results unordered.
+//
+// # Return Value
+//
+//   - iter.Seq2[Result, error]: A sequence of results paired with their error
+//     (the same value as Result.Err). If the context ends before all names are
+//     greeted, the sequence ends with a Result whose Index is -1, paired with
+//     ErrContextCanceled or ErrContextDeadlineExceeded. Breaking out of the loop
+//     stops the stream and releases its worker goroutines, even if the names
+//     sequence does not return; only the goroutine reading it is left behind.
+//
+// # Example Usage
+//
+//	for result, err := range greeting.GreetStream(ctx, slices.Values(names), greeting.StreamOptions{Ordered: true}) {
+//	    if err != nil {
+//	        log.Printf("Failed to greet %q: %v", result.Name, err)
+//	        continue
+//	    }
+//	    fmt.Print(result.Message)
+//	}
+func GreetStream(ctx context.Context, names iter.Seq[string], opts StreamOptions) iter.Seq2[Result, error] {
+	return stream(ctx, func(context.Context) iter.Seq[string] { return names }, opts)
+}
+
+// GreetChan is like GreetStream, but reads the names from a channel until it is
+// closed or the stream stops.
+//
+// # Example Usage
+//
+//	names := make(chan string)
+//	go produceNames(names) // closes names when done
+//	for result, err := range greeting.GreetChan(ctx, names, greeting.StreamOptions{}) {
+//	    // ...
+//	}
+func GreetChan(ctx context.Context, names <-chan string, opts StreamOptions) iter.Seq2[Result, error] {
+	return stream(ctx, func(ctx context.Context) iter.Seq[string] {
+		return func(yield func(string) bool) {
+			for {
+				select {
+				case name, ok := <-names:
+					if !ok || !yield(name) {
+						return
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}, opts)
+}
+
+// stream implements GreetStream and GreetChan. The source function receives the
+// stream's internal context, which is canceled when the stream stops, so that
+// blocking sources can stop producing names.
+func stream(parent context.Context, source func(context.Context) iter.Seq[string], opts StreamOptions) iter.Seq2[Result, error] {
+	greeter := opts.Greeter
+	if greeter == nil {
+		greeter = DefaultGreeter
+	}
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultBatchConcurrency
+	}
+	buffer := opts.Buffer
+	if buffer <= 0 {
+		buffer = workers
+	}
+
+	return func(yield func(Result, error) bool) {
+		ctx, cancel := context.WithCancel(parent)
+		defer cancel()
+
+		// window bounds the number of names that are in flight or waiting for the consumer.
+		window := make(chan struct{}, workers+buffer)
+		jobs := make(chan Result)
+		out := make(chan Result, buffer)
+
+		// read counts the names handed to the workers; exhausted is set once the
+		// source has ended by itself rather than because the stream stopped.
+		var read atomic.Int64
+		var exhausted atomic.Bool
+		go func() {
+			defer close(jobs)
+			for name := range source(ctx) {
+				select {
+				case window <- struct{}{}:
+				case <-ctx.Done():
+					return
+				}
+				select {
+				case jobs <- Result{Index: int(read.Load()), Name: name}:
+				case <-ctx.Done():
+					return
+				}
+				read.Add(1)
+			}
+			exhausted.Store(ctx.Err() == nil)
+		}()
+
+		var wg sync.WaitGroup
+		for range workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					// Don't depend on the source returning, which may ignore ctx.
+					var r Result
+					var ok bool
+					select {
+					case r, ok = <-jobs:
+						if !ok {
+							return
+						}
+					case <-ctx.Done():
+						return
+					}
+					r.Message, r.Err = greeter.Greet(ctx, r.Name)
+					select {
+					case out <- r:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+		}
+		go func() {
+			wg.Wait()
+			close(out)
+		}()
+
+		// Stop the goroutines and wait for them before returning.
+		defer func() {
+			cancel()
+			for range out {
+			}
+		}()
+
+		pending := make(map[int]Result)
+		// next is the number of results yielded so far.
+		next := 0
+		for r := range out {
+			if parent.Err() != nil {
+				break
+			}
+			if !opts.Ordered {
+				<-window
+				next++
+				if !yield(r, r.Err) {
+					return
+				}
+				continue
+			}
+
+			pending[r.Index] = r
+			for {
+				head, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				<-window
+				if !yield(head, head.Err) {
+					return
+				}
+			}
+		}
+
+		// Report the cancellation only if it cut the stream short.
+		if parent.Err() != nil && (!exhausted.Load() || int64(next) < read.Load()) {
+			yield(Result{Index: -1}, mapContextError("GreetStream", parent.Err()))
+		}
+	}
+}
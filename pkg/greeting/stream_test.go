@@ -0,0 +1,201 @@
+package greeting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGreetStreamOrdered(t *testing.T) {
+	// Later names finish first.
+	g := GreeterFunc(func(ctx context.Context, name string) (string, error) {
+		var n int
+		fmt.Sscanf(name, "%d", &n)
+		time.Sleep(time.Duration(10-n) * time.Millisecond)
+		return name, nil
+	})
+
+	names := []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}
+	var got []string
+	for r, err := range GreetStream(context.Background(), slices.Values(names), StreamOptions{Concurrency: 10, Greeter: g, Ordered: true}) {
+		assert.NoError(t, err)
+		assert.Equal(t, len(got), r.Index)
+		got = append(got, r.Message)
+	}
+	assert.Equal(t, names, got)
+}
+
+func TestGreetStreamUnordered(t *testing.T) {
+	g, err := NewGreeter(WithProcessingDelay(0))
+	assert.NoError(t, err)
+
+	names := []string{"John", "", "Alice", "Bob"}
+	results := make(map[int]Result)
+	for r, err := range GreetStream(context.Background(), slices.Values(names), StreamOptions{Concurrency: 2, Greeter: g}) {
+		assert.Equal(t, r.Err, err)
+		results[r.Index] = r
+	}
+
+	if assert.Len(t, results, len(names)) {
+		for i, r := range results {
+			assert.Equal(t, names[i], r.Name)
+		}
+		assert.Equal(t, "Howdy John!\n", results[0].Message)
+		assert.True(t, errors.Is(results[1].Err, ErrInvalidName), "Per-item errors should be reported")
+		assert.Equal(t, "Howdy Bob!\n", results[3].Message)
+	}
+}
+
+func TestGreetChan(t *testing.T) {
+	g, err := NewGreeter(WithProcessingDelay(0))
+	assert.NoError(t, err)
+
+	names := make(chan string)
+	go func() {
+		defer close(names)
+		for _, name := range []string{"John", "Alice"} {
+			names <- name
+		}
+	}()
+
+	var got []string
+	for r, err := range GreetChan(context.Background(), names, StreamOptions{Greeter: g, Ordered: true}) {
+		assert.NoError(t, err)
+		got = append(got, r.Message)
+	}
+	assert.Equal(t, []string{"Howdy John!\n", "Howdy Alice!\n"}, got)
+}
+
+func TestGreetStreamBackpressure(t *testing.T) {
+	var read atomic.Int32
+	names := func(yield func(string) bool) {
+		for i := 0; ; i++ {
+			read.Add(1)
+			if !yield(fmt.Sprint(i)) {
+				return
+			}
+		}
+	}
+	g := GreeterFunc(func(ctx context.Context, name string) (string, error) { return name, nil })
+
+	next, stop := iter.Pull2(GreetStream(context.Background(), names, StreamOptions{Concurrency: 2, Buffer: 3, Greeter: g}))
+	defer stop()
+
+	_, _, ok := next()
+	assert.True(t, ok)
+	time.Sleep(20 * time.Millisecond)
+
+	// One result was consumed and at most Concurrency+Buffer names are in flight;
+	// the producer may additionally hold one name while it waits for capacity.
+	assert.LessOrEqual(t, read.Load(), int32(1+2+3+1))
+}
+
+func TestGreetStreamBreak(t *testing.T) {
+	var greeted atomic.Int32
+	g := GreeterFunc(func(ctx context.Context, name string) (string, error) {
+		greeted.Add(1)
+		return name, nil
+	})
+	names := func(yield func(string) bool) {
+		for i := 0; ; i++ {
+			if !yield(fmt.Sprint(i)) {
+				return
+			}
+		}
+	}
+
+	count := 0
+	for range GreetStream(context.Background(), names, StreamOptions{Concurrency: 4, Greeter: g}) {
+		count++
+		if count == 5 {
+			break
+		}
+	}
+	assert.Equal(t, 5, count)
+
+	// The stream's goroutines have stopped once the loop returns.
+	n := greeted.Load()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, n, greeted.Load())
+}
+
+func TestGreetStreamCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g := GreeterFunc(func(ctx context.Context, name string) (string, error) {
+		if name == "stop" {
+			cancel()
+		}
+		<-ctx.Done()
+		return "", mapContextError("Greet", ctx.Err())
+	})
+
+	var last Result
+	var lastErr error
+	for r, err := range GreetStream(ctx, slices.Values([]string{"John", "stop", "Alice"}), StreamOptions{Concurrency: 3, Greeter: g}) {
+		last, lastErr = r, err
+	}
+	assert.Equal(t, -1, last.Index)
+	assert.True(t, errors.Is(lastErr, ErrContextCanceled), "Expected ErrContextCanceled, got %v", lastErr)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	names := make(chan string) // Never sends or closes.
+	for r, err := range GreetChan(ctx, names, StreamOptions{}) {
+		last, lastErr = r, err
+	}
+	assert.Equal(t, -1, last.Index)
+	assert.True(t, errors.Is(lastErr, ErrContextDeadlineExceeded), "Expected ErrContextDeadlineExceeded, got %v", lastErr)
+}
+
+func TestGreetStreamBreakWithBlockingSource(t *testing.T) {
+	g := GreeterFunc(func(ctx context.Context, name string) (string, error) { return name, nil })
+	release := make(chan struct{})
+	defer close(release)
+	// The source ignores the stream's context and blocks after the first name.
+	names := func(yield func(string) bool) {
+		if yield("John") {
+			<-release
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range GreetStream(context.Background(), names, StreamOptions{Concurrency: 2, Greeter: g}) {
+			break
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Breaking out of the loop should not wait for the source to return")
+	}
+}
+
+func TestGreetStreamCancellationAfterLastResult(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sourceDone := make(chan struct{})
+	names := func(yield func(string) bool) {
+		defer close(sourceDone)
+		yield("John")
+	}
+	g := GreeterFunc(func(ctx context.Context, name string) (string, error) {
+		<-sourceDone
+		return name, nil
+	})
+
+	var errs []error
+	for _, err := range GreetStream(ctx, names, StreamOptions{Concurrency: 2, Greeter: g}) {
+		errs = append(errs, err)
+		cancel()
+	}
+	assert.Equal(t, []error{nil}, errs, "A cancellation after the last result should not be reported")
+}
@@ -0,0 +1,62 @@
+package greeting
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dustin/go-humanize"
+)
+
+// MaxWinnings is the largest winnings amount, in cents, that can be announced:
+// one billion dollars. Larger amounts are rejected with ErrInvalidWinnings, as
+// they are far beyond any prize and most likely the result of a bug.
+const MaxWinnings uint64 = 100_000_000_000
+
+// GreetWithWinnings generates a personalized greeting message that announces the
+// amount the recipient has won.
+//
+// # Function Purpose
+//
+// This function behaves exactly like Greet, but appends the recipient's winnings
+// to the message. The amount is humanized with thousand separators and two decimal
+// places so that it can be shown to end users as-is.
+//
+// # Parameters
+//
+//   - ctx: A context.Context that can be used to cancel the operation or set a deadline.
+//
+//   - name: The name of the person to greet. This must be a non-empty string.
+//     If an empty string is provided, ErrInvalidName will be returned.
+//
+//   - winnings: The amount won, in cents (e.g., 1234567 = $12,345.67).
+//     Amounts greater than MaxWinnings cause ErrInvalidWinnings to be returned.
+//
+// # Return Values
+//
+//   - string: The formatted greeting message if successful.
+//     Example: "Howdy John! You have won $12,345.67 USD!"
+//
+// - error: An error if something went wrong. Possible errors include:
+//   - ErrInvalidName: If the name parameter is empty
+//   - ErrInvalidWinnings: If the winnings amount exceeds MaxWinnings
+//   - ErrContextCanceled: If the context was canceled during processing
+//   - ErrContextDeadlineExceeded: If the context deadline was exceeded
+//
+// # Example Usage
+//
+//	ctx := context.Background()
+//	message, err := greeting.GreetWithWinnings(ctx, "John", 1234567)
+//	if err != nil {
+//	    log.Fatalf("Error: %v", err)
+//	}
+//	fmt.Println(message)
+//	// Output: Howdy John! You have won $12,345.67 USD!
+func GreetWithWinnings(ctx context.Context, name string, winnings uint64) (string, error) {
+	return DefaultGreeter.GreetWithWinnings(ctx, name, winnings)
+}
+
+// formatWinnings formats an amount in cents as US dollars with thousand separators,
+// for example 1234567 becomes "$12,345.67 USD".
+func formatWinnings(cents uint64) string {
+	return fmt.Sprintf("$%s.%02d USD", humanize.Comma(int64(cents/100)), cents%100)
+}
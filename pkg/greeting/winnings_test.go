@@ -0,0 +1,127 @@
+package greeting
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGreetWithWinnings(t *testing.T) {
+	tests := []struct {
+		name        string
+		setupCtx    func() (context.Context, context.CancelFunc)
+		input       string
+		winnings    uint64
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:     "valid input",
+			setupCtx: func() (context.Context, context.CancelFunc) { return context.Background(), func() {} },
+			input:    "John",
+			winnings: 1234567,
+			expected: "Howdy John! You have won $12,345.67 USD!\n",
+		},
+		{
+			name:     "zero winnings",
+			setupCtx: func() (context.Context, context.CancelFunc) { return context.Background(), func() {} },
+			input:    "Alice",
+			winnings: 0,
+			expected: "Howdy Alice! You have won $0.00 USD!\n",
+		},
+		{
+			name:     "maximum winnings",
+			setupCtx: func() (context.Context, context.CancelFunc) { return context.Background(), func() {} },
+			input:    "Alice",
+			winnings: MaxWinnings,
+			expected: "Howdy Alice! You have won $1,000,000,000.00 USD!\n",
+		},
+		{
+			name:        "winnings out of range",
+			setupCtx:    func() (context.Context, context.CancelFunc) { return context.Background(), func() {} },
+			input:       "John",
+			winnings:    MaxWinnings + 1,
+			expectedErr: ErrInvalidWinnings,
+		},
+		{
+			name:        "empty name",
+			setupCtx:    func() (context.Context, context.CancelFunc) { return context.Background(), func() {} },
+			input:       "",
+			winnings:    100,
+			expectedErr: ErrInvalidName,
+		},
+		{
+			name: "canceled context before call",
+			setupCtx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			input:       "John",
+			winnings:    100,
+			expectedErr: ErrContextCanceled,
+		},
+		{
+			name: "canceled context before amount validation",
+			setupCtx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			input:       "John",
+			winnings:    MaxWinnings + 1,
+			expectedErr: ErrContextCanceled,
+		},
+		{
+			name: "context deadline exceeded during processing",
+			setupCtx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 10*time.Millisecond)
+			},
+			input:       "John",
+			winnings:    100,
+			expectedErr: ErrContextDeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := tt.setupCtx()
+			defer cancel()
+
+			result, err := GreetWithWinnings(ctx, tt.input, tt.winnings)
+
+			if tt.expectedErr != nil {
+				assert.True(t, errors.Is(err, tt.expectedErr), "Expected error %v, got %v", tt.expectedErr, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestFormatWinnings(t *testing.T) {
+	tests := []struct {
+		cents    uint64
+		expected string
+	}{
+		{0, "$0.00 USD"},
+		{5, "$0.05 USD"},
+		{99, "$0.99 USD"},
+		{100, "$1.00 USD"},
+		{123456, "$1,234.56 USD"},
+		{1234567, "$12,345.67 USD"},
+		{100000000, "$1,000,000.00 USD"},
+		{math.MaxUint64, "$184,467,440,737,095,516.15 USD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatWinnings(tt.cents))
+		})
+	}
+}
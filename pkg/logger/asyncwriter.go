@@ -0,0 +1,302 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed is returned when writing to a writer of this package after it was closed.
+var ErrClosed = errors.New("logger: writer is closed")
+
+// DefaultQueueSize is the number of messages an AsyncWriter queues unless
+// configured otherwise with WithQueueSize.
+const DefaultQueueSize = 1024
+
+// DropPolicy determines what an AsyncWriter does with a message when its queue
+// is full.
+type DropPolicy int
+
+const (
+	// DropNewest discards the message being written, keeping the queued ones. It
+	// is the default policy.
+	DropNewest DropPolicy = iota
+	// DropOldest discards the oldest queued message to make room for the one
+	// being written, so that the most recent messages are kept.
+	DropOldest
+	// Block makes the caller wait until there is room in the queue. No message is
+	// lost, but callers are slowed down to the speed of the underlying writer.
+	Block
+)
+
+// String returns the name of the policy, e.g. "DropOldest".
+func (p DropPolicy) String() string {
+	switch p {
+	case DropNewest:
+		return "DropNewest"
+	case DropOldest:
+		return "DropOldest"
+	case Block:
+		return "Block"
+	default:
+		return fmt.Sprintf("DropPolicy(%d)", int(p))
+	}
+}
+
+// AsyncWriter is an io.Writer that queues messages in memory and writes them to
+// another writer in a background goroutine, so that logging does not block the
+// caller on a slow sink such as a terminal, a network connection or a full disk.
+//
+// # Usage
+//
+// Wrap the writer of a log.Logger and close the AsyncWriter during shutdown, so
+// that queued messages are written before the program exits:
+//
+//	async := logger.NewAsyncWriter(os.Stderr, logger.WithDropPolicy(logger.DropOldest))
+//	ctxLogger := logger.NewContextLogger(log.New(async, "", log.LstdFlags))
+//	defer func() {
+//	    ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+//	    defer cancel()
+//	    async.Close(ctx)
+//	}()
+//
+// The queue is bounded. When it is full, messages are handled according to the
+// DropPolicy, and Dropped reports how many messages were discarded. The
+// background goroutine takes all queued messages at once and writes them while
+// new ones are queued, so up to twice the queue size may be held in memory. Errors of
+// the underlying writer are not reported to the caller, whose Write call has
+// already returned; use WithErrorHandler to observe them.
+//
+// An AsyncWriter is safe for concurrent use by multiple goroutines.
+type AsyncWriter struct {
+	w         io.Writer
+	queueSize int
+	policy    DropPolicy
+	onError   func(err error)
+	dropped   atomic.Uint64
+
+	mu sync.Mutex
+	// changed is signaled whenever the queue or the closed flag changes.
+	changed *sync.Cond
+	queue   [][]byte
+	// queued is the sequence number of the last queued message; messages are
+	// numbered from 1 in queue order, so the queue always holds the messages
+	// queued-len(queue)+1 to queued. written is the sequence number up to which
+	// every message has been written or discarded by DropOldest.
+	queued  uint64
+	written uint64
+	// progress, if not nil, is closed when written advances, to wake Flush.
+	progress chan struct{}
+	closed   bool
+	// done is closed when the background goroutine has exited.
+	done chan struct{}
+}
+
+// AsyncOption configures an AsyncWriter created by NewAsyncWriter.
+type AsyncOption func(*AsyncWriter)
+
+// WithQueueSize sets the maximum number of queued messages, not counting the
+// messages the background goroutine is writing. Sizes below 1 are ignored.
+func WithQueueSize(size int) AsyncOption {
+	return func(a *AsyncWriter) {
+		if size > 0 {
+			a.queueSize = size
+		}
+	}
+}
+
+// WithDropPolicy sets what happens to messages written while the queue is full.
+func WithDropPolicy(policy DropPolicy) AsyncOption {
+	return func(a *AsyncWriter) { a.policy = policy }
+}
+
+// WithErrorHandler sets a function that is called with the error of every failed
+// write to the underlying writer, including writes that panicked. It is called
+// from the background goroutine, one error at a time, and must not write to the
+// AsyncWriter itself.
+func WithErrorHandler(handle func(err error)) AsyncOption {
+	return func(a *AsyncWriter) { a.onError = handle }
+}
+
+// NewAsyncWriter creates an AsyncWriter that writes to w and starts its
+// background goroutine, which runs until the writer is closed. By default, up to
+// DefaultQueueSize messages are queued and new messages are dropped when the
+// queue is full.
+//
+// # Parameters
+//
+// - w: The writer that receives the messages, e.g. os.Stderr or a file.
+//
+// - opts: Optional settings such as WithQueueSize, WithDropPolicy and WithErrorHandler.
+//
+// # Return Value
+//
+// - *AsyncWriter: The running writer. Close it to stop the background goroutine.
+func NewAsyncWriter(w io.Writer, opts ...AsyncOption) *AsyncWriter {
+	a := &AsyncWriter{
+		w:         w,
+		queueSize: DefaultQueueSize,
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.changed = sync.NewCond(&a.mu)
+
+	go a.run()
+	return a
+}
+
+// Write queues a copy of p and returns without waiting for it to be written,
+// unless the queue is full and the policy is Block. A message discarded because
+// the queue is full still counts as written, so that callers such as log.Logger
+// do not treat it as an error; see Dropped.
+//
+// Write returns ErrClosed after the writer was closed.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	msg := append([]byte(nil), p...)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.closed {
+		return 0, ErrClosed
+	}
+
+	if len(a.queue) >= a.queueSize {
+		switch a.policy {
+		case DropOldest:
+			a.queue[0] = nil
+			a.queue = append(a.queue[1:], msg)
+			a.dropped.Add(1)
+			a.queued++
+			return len(p), nil
+		case Block:
+			for len(a.queue) >= a.queueSize && !a.closed {
+				a.changed.Wait()
+			}
+			if a.closed {
+				return 0, ErrClosed
+			}
+		default:
+			a.dropped.Add(1)
+			return len(p), nil
+		}
+	}
+
+	a.queue = append(a.queue, msg)
+	a.queued++
+	a.changed.Broadcast()
+	return len(p), nil
+}
+
+// Dropped returns the number of messages discarded because the queue was full.
+func (a *AsyncWriter) Dropped() uint64 {
+	return a.dropped.Load()
+}
+
+// Flush waits until every message queued before the call has been written to
+// the underlying writer, or discarded by DropOldest, or until ctx is done.
+// Messages queued while Flush waits do not prolong the wait.
+//
+// # Return Value
+//
+// - error: ctx.Err() if the context ended before those messages were written, otherwise nil.
+func (a *AsyncWriter) Flush(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	target := a.queued
+	for a.written < target {
+		if a.progress == nil {
+			a.progress = make(chan struct{})
+		}
+		progress := a.progress
+		a.mu.Unlock()
+		select {
+		case <-progress:
+		case <-ctx.Done():
+			a.mu.Lock()
+			return ctx.Err()
+		}
+		a.mu.Lock()
+	}
+	return nil
+}
+
+// advance records that every message up to sequence number seq has been written
+// or discarded, waking Flush. The caller must hold a.mu.
+func (a *AsyncWriter) advance(seq uint64) {
+	a.written = seq
+	if a.progress != nil {
+		close(a.progress)
+		a.progress = nil
+	}
+}
+
+// Close stops accepting messages, waits until the queued messages have been
+// written or ctx is done, and stops the background goroutine once the queue is
+// empty. Writers blocked by the Block policy return ErrClosed. Close does not
+// close the underlying writer. Calling Close more than once is safe.
+//
+// # Return Value
+//
+// - error: ctx.Err() if the context ended before the queue was drained, otherwise nil.
+func (a *AsyncWriter) Close(ctx context.Context) error {
+	a.mu.Lock()
+	a.closed = true
+	a.changed.Broadcast()
+	a.mu.Unlock()
+
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run writes the queued messages until the writer is closed and the queue is empty.
+func (a *AsyncWriter) run() {
+	defer close(a.done)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for {
+		for len(a.queue) == 0 && !a.closed {
+			a.changed.Wait()
+		}
+		if len(a.queue) == 0 {
+			return
+		}
+
+		// Messages discarded by DropOldest while the batch is written precede
+		// the messages that replace them, so they are done once those are.
+		batch, last := a.queue, a.queued
+		a.queue = nil
+		a.changed.Broadcast()
+		a.mu.Unlock()
+
+		for _, msg := range batch {
+			if err := a.write(msg); err != nil && a.onError != nil {
+				a.onError(err)
+			}
+		}
+
+		a.mu.Lock()
+		a.advance(last)
+	}
+}
+
+// write writes a message to the underlying writer, turning a panic into an
+// error, so that a faulty writer does not take the background goroutine down.
+func (a *AsyncWriter) write(msg []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("logger: writer panicked: %v", r)
+		}
+	}()
+	_, err = a.w.Write(msg)
+	return err
+}
@@ -0,0 +1,284 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// gatedWriter is a writer whose writes block until the gate is opened. It
+// reports each write that has started on started.
+type gatedWriter struct {
+	gate    chan struct{}
+	started chan struct{}
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func newGatedWriter() *gatedWriter {
+	return &gatedWriter{gate: make(chan struct{}), started: make(chan struct{}, 100)}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	w.started <- struct{}{}
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gatedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+// fillQueue writes a message that the background goroutine picks up and blocks
+// on, followed by n queued messages.
+func fillQueue(t *testing.T, a *AsyncWriter, w *gatedWriter, n int) {
+	t.Helper()
+	_, err := a.Write([]byte("busy\n"))
+	assert.NoError(t, err)
+	<-w.started
+	for i := 0; i < n; i++ {
+		_, err := fmt.Fprintf(a, "msg %d\n", i)
+		assert.NoError(t, err)
+	}
+}
+
+func TestAsyncWriter(t *testing.T) {
+	var buf bytes.Buffer
+	async := NewAsyncWriter(&buf)
+	ctxLogger := NewContextLogger(log.New(async, "", 0))
+
+	for i := 0; i < 10; i++ {
+		ctxLogger.Info(context.Background(), "Message %d", i)
+	}
+	assert.NoError(t, async.Flush(context.Background()))
+	assert.Equal(t, 10, bytes.Count(buf.Bytes(), []byte("\n")))
+	assert.Contains(t, buf.String(), "INFO: Message 0\nINFO: Message 1\n")
+
+	assert.NoError(t, async.Close(context.Background()))
+	assert.NoError(t, async.Close(context.Background()), "Close is idempotent")
+	_, err := async.Write([]byte("late\n"))
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.Equal(t, uint64(0), async.Dropped())
+}
+
+func TestAsyncWriterCopiesMessages(t *testing.T) {
+	w := newGatedWriter()
+	async := NewAsyncWriter(w)
+
+	msg := []byte("first\n")
+	_, _ = async.Write(msg)
+	copy(msg, "xxxxx\n")
+	close(w.gate)
+	assert.NoError(t, async.Close(context.Background()))
+	assert.Equal(t, "first\n", w.String())
+}
+
+func TestAsyncWriterDropPolicies(t *testing.T) {
+	tests := []struct {
+		policy   DropPolicy
+		expected string
+	}{
+		{DropNewest, "busy\nmsg 0\nmsg 1\n"},
+		{DropOldest, "busy\nmsg 3\nmsg 4\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			w := newGatedWriter()
+			async := NewAsyncWriter(w, WithQueueSize(2), WithDropPolicy(tt.policy))
+
+			fillQueue(t, async, w, 5)
+			assert.Equal(t, uint64(3), async.Dropped())
+
+			close(w.gate)
+			assert.NoError(t, async.Close(context.Background()))
+			assert.Equal(t, tt.expected, w.String())
+		})
+	}
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+	w := newGatedWriter()
+	async := NewAsyncWriter(w, WithQueueSize(1), WithDropPolicy(Block))
+	fillQueue(t, async, w, 1)
+
+	written := make(chan error)
+	go func() {
+		_, err := async.Write([]byte("blocked\n"))
+		written <- err
+	}()
+	select {
+	case <-written:
+		t.Fatal("Write should block while the queue is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(w.gate)
+	assert.NoError(t, <-written)
+	assert.NoError(t, async.Close(context.Background()))
+	assert.Equal(t, "busy\nmsg 0\nblocked\n", w.String())
+	assert.Equal(t, uint64(0), async.Dropped())
+}
+
+func TestAsyncWriterCloseReleasesBlockedWriters(t *testing.T) {
+	w := newGatedWriter()
+	async := NewAsyncWriter(w, WithQueueSize(1), WithDropPolicy(Block))
+	fillQueue(t, async, w, 1)
+
+	written := make(chan error)
+	go func() {
+		_, err := async.Write([]byte("blocked\n"))
+		written <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, async.Close(ctx), context.DeadlineExceeded, "The queue cannot drain while the writer is stuck")
+	assert.ErrorIs(t, <-written, ErrClosed)
+
+	close(w.gate)
+	assert.NoError(t, async.Close(context.Background()))
+	assert.Equal(t, "busy\nmsg 0\n", w.String())
+}
+
+func TestAsyncWriterFlushTimeout(t *testing.T) {
+	w := newGatedWriter()
+	async := NewAsyncWriter(w)
+	fillQueue(t, async, w, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, async.Flush(ctx), context.DeadlineExceeded)
+
+	close(w.gate)
+	assert.NoError(t, async.Flush(context.Background()))
+	assert.Equal(t, "busy\nmsg 0\n", w.String())
+	assert.NoError(t, async.Close(context.Background()))
+}
+
+func TestAsyncWriterFlushWaitsForMessagesBeingWritten(t *testing.T) {
+	w := newGatedWriter()
+	async := NewAsyncWriter(w, WithQueueSize(1), WithDropPolicy(DropOldest))
+	fillQueue(t, async, w, 1)
+
+	flushed := make(chan error, 1)
+	go func() { flushed <- async.Flush(context.Background()) }()
+	select {
+	case err := <-flushed:
+		t.Fatalf("Flush returned before the messages were written: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	for i := 1; i <= 2; i++ {
+		_, err := fmt.Fprintf(async, "msg %d\n", i)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, uint64(2), async.Dropped())
+	select {
+	case err := <-flushed:
+		t.Fatalf("Dropping queued messages should not complete the message being written: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(w.gate)
+	assert.NoError(t, <-flushed)
+	assert.Equal(t, "busy\nmsg 2\n", w.String())
+	assert.NoError(t, async.Close(context.Background()))
+}
+
+// slowWriter is a writer that takes a millisecond per write.
+type slowWriter struct{}
+
+func (slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(time.Millisecond)
+	return len(p), nil
+}
+
+func TestAsyncWriterFlushUnderSteadyLogging(t *testing.T) {
+	async := NewAsyncWriter(slowWriter{}, WithQueueSize(16))
+	_, _ = async.Write([]byte("before flush\n"))
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_, _ = async.Write([]byte("steady\n"))
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	assert.NoError(t, async.Flush(ctx), "Flush should not wait for messages queued after the call")
+
+	close(stop)
+	<-stopped
+	assert.NoError(t, async.Close(context.Background()))
+}
+
+func TestAsyncWriterConcurrentUse(t *testing.T) {
+	var buf bytes.Buffer
+	async := NewAsyncWriter(&buf, WithQueueSize(8), WithDropPolicy(Block))
+	ctxLogger := NewContextLogger(log.New(async, "", 0))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ctxLogger.Info(context.Background(), "Worker %d message %d", i, j)
+				if j%25 == 0 {
+					_ = async.Flush(context.Background())
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.NoError(t, async.Close(context.Background()))
+	assert.Equal(t, 800, bytes.Count(buf.Bytes(), []byte("\n")))
+}
+
+// failingWriter is a writer whose writes fail.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestAsyncWriterErrorHandler(t *testing.T) {
+	var errs []error
+	async := NewAsyncWriter(failingWriter{}, WithErrorHandler(func(err error) { errs = append(errs, err) }))
+
+	_, err := async.Write([]byte("first\n"))
+	assert.NoError(t, err, "Write should not report errors of the underlying writer")
+	_, err = async.Write([]byte("second\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, async.Close(context.Background()))
+	assert.Len(t, errs, 2)
+	assert.EqualError(t, errs[0], "disk full")
+}
+
+func TestDropPolicyString(t *testing.T) {
+	assert.Equal(t, "DropNewest", DropNewest.String())
+	assert.Equal(t, "DropOldest", DropOldest.String())
+	assert.Equal(t, "Block", Block.String())
+	assert.Equal(t, "DropPolicy(9)", DropPolicy(9).String())
+}
@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync/atomic"
+)
+
+// AtomicLevel is a minimum log level that can be read and changed concurrently.
+// Several loggers can share one AtomicLevel, so that changing it affects all of
+// them at once, for example to turn on debug logging in a running process.
+//
+// # Usage
+//
+// Create an AtomicLevel with NewAtomicLevel and pass it to the loggers with
+// WithAtomicLevel. Every logger has an AtomicLevel, which is returned by its
+// AtomicLevel method. AtomicLevel also implements http.Handler, so it can be
+// exposed on an administrative endpoint:
+//
+//	level := logger.NewAtomicLevel(logger.LevelInfo)
+//	ctxLogger := logger.NewContextLogger(nil, logger.WithAtomicLevel(level))
+//	http.Handle("/loglevel", level)
+//
+// An AtomicLevel is safe for concurrent use by multiple goroutines.
+type AtomicLevel struct {
+	level atomic.Int64
+}
+
+// NewAtomicLevel creates an AtomicLevel set to the given level.
+func NewAtomicLevel(level Level) *AtomicLevel {
+	a := &AtomicLevel{}
+	a.SetLevel(level)
+	return a
+}
+
+// Level returns the current minimum level.
+func (a *AtomicLevel) Level() Level {
+	return Level(a.level.Load())
+}
+
+// SetLevel changes the minimum level. The change is immediately visible to
+// every logger sharing the AtomicLevel.
+func (a *AtomicLevel) SetLevel(level Level) {
+	a.level.Store(int64(level))
+}
+
+// Enabled reports whether messages of the given level are logged.
+func (a *AtomicLevel) Enabled(level Level) bool {
+	return level >= a.Level()
+}
+
+// maxLevelBodySize limits the size of the request bodies read by ServeHTTP. A
+// level document is a few bytes long, so anything larger is rejected.
+const maxLevelBodySize = 1 << 10
+
+// levelPayload is the JSON document read and written by ServeHTTP.
+type levelPayload struct {
+	Level *Level `json:"level,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ServeHTTP reads or updates the level over HTTP.
+//
+// # Requests
+//
+// - GET returns the current level, e.g. {"level":"INFO"}.
+// - PUT sets the level from a body such as {"level":"debug"} and returns the new level.
+//
+// Malformed bodies and unknown level names are rejected with 400 Bad Request,
+// bodies larger than 1 KiB with 413 Request Entity Too Large and other methods
+// with 405 Method Not Allowed. Errors are reported as {"error":"..."}.
+//
+// # Example
+//
+//	curl -X PUT -d '{"level":"debug"}' http://localhost:6060/loglevel
+//	// {"level":"DEBUG"}
+func (a *AtomicLevel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req levelPayload
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLevelBodySize)).Decode(&req); err != nil {
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			writeLevelResponse(w, status, levelPayload{Error: err.Error()})
+			return
+		}
+		if req.Level == nil {
+			writeLevelResponse(w, http.StatusBadRequest, levelPayload{Error: "missing level"})
+			return
+		}
+		a.SetLevel(*req.Level)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeLevelResponse(w, http.StatusMethodNotAllowed, levelPayload{Error: "method not allowed"})
+		return
+	}
+
+	level := a.Level()
+	writeLevelResponse(w, http.StatusOK, levelPayload{Level: &level})
+}
+
+// writeLevelResponse writes payload as a JSON response with the given status.
+func writeLevelResponse(w http.ResponseWriter, status int, payload levelPayload) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(payload)
+}
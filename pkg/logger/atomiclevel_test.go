@@ -0,0 +1,148 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomicLevel(t *testing.T) {
+	level := NewAtomicLevel(LevelWarning)
+	assert.Equal(t, LevelWarning, level.Level())
+	assert.False(t, level.Enabled(LevelInfo))
+	assert.True(t, level.Enabled(LevelError))
+
+	level.SetLevel(LevelDebug)
+	assert.Equal(t, LevelDebug, level.Level())
+	assert.True(t, level.Enabled(LevelDebug))
+}
+
+func TestAtomicLevelSharedByLoggers(t *testing.T) {
+	var buf1, buf2 bytes.Buffer
+	level := NewAtomicLevel(LevelInfo)
+	logger1 := NewContextLogger(log.New(&buf1, "", 0), WithAtomicLevel(level))
+	logger2 := NewContextLogger(log.New(&buf2, "", 0), WithAtomicLevel(level))
+	assert.Same(t, level, logger1.AtomicLevel())
+
+	ctx := context.Background()
+	logger1.Debug(ctx, "test debug message")
+	assert.Empty(t, buf1.String())
+
+	// Changing the level through one logger affects both
+	logger2.SetLevel(LevelDebug)
+	assert.Equal(t, LevelDebug, logger1.Level())
+	logger1.Debug(ctx, "test debug message")
+	logger2.Debug(ctx, "test debug message")
+	assert.Equal(t, "DEBUG: test debug message\n", buf1.String())
+	assert.Equal(t, "DEBUG: test debug message\n", buf2.String())
+}
+
+func TestAtomicLevelConcurrentUse(t *testing.T) {
+	var buf bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&buf, "", 0))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			ctxLogger.SetLevel(LevelDebug)
+			ctxLogger.SetLevel(LevelInfo)
+		}()
+		go func() {
+			defer wg.Done()
+			ctxLogger.Debug(context.Background(), "test debug message")
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, LevelInfo, ctxLogger.Level())
+}
+
+func TestAtomicLevelServeHTTP(t *testing.T) {
+	level := NewAtomicLevel(LevelInfo)
+
+	tests := []struct {
+		name           string
+		method         string
+		body           string
+		expectedStatus int
+		expectedBody   string
+		expectedLevel  Level
+	}{
+		{
+			name:           "get level",
+			method:         http.MethodGet,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"level":"INFO"}`,
+			expectedLevel:  LevelInfo,
+		},
+		{
+			name:           "put level",
+			method:         http.MethodPut,
+			body:           `{"level":"debug"}`,
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"level":"DEBUG"}`,
+			expectedLevel:  LevelDebug,
+		},
+		{
+			name:           "put unknown level",
+			method:         http.MethodPut,
+			body:           `{"level":"verbose"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"unknown log level \"verbose\""}`,
+			expectedLevel:  LevelDebug,
+		},
+		{
+			name:           "put missing level",
+			method:         http.MethodPut,
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedBody:   `{"error":"missing level"}`,
+			expectedLevel:  LevelDebug,
+		},
+		{
+			name:           "put malformed body",
+			method:         http.MethodPut,
+			body:           `level=debug`,
+			expectedStatus: http.StatusBadRequest,
+			expectedLevel:  LevelDebug,
+		},
+		{
+			name:           "put oversized body",
+			method:         http.MethodPut,
+			body:           `{"level":"error","padding":"` + strings.Repeat("x", maxLevelBodySize) + `"}`,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedBody:   `{"error":"http: request body too large"}`,
+			expectedLevel:  LevelDebug,
+		},
+		{
+			name:           "unsupported method",
+			method:         http.MethodPost,
+			body:           `{"level":"error"}`,
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedBody:   `{"error":"method not allowed"}`,
+			expectedLevel:  LevelDebug,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			level.ServeHTTP(rec, httptest.NewRequest(tt.method, "/loglevel", strings.NewReader(tt.body)))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, rec.Body.String())
+			}
+			assert.Equal(t, tt.expectedLevel, level.Level())
+		})
+	}
+}
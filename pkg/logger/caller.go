@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"log"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// maxStackDepth is the maximum number of frames captured for a stack trace.
+const maxStackDepth = 64
+
+// WithCaller makes the logger record the file and line of the code that logged
+// each message in Entry.Caller, e.g. "greeting/greeter.go:274". The encoders
+// render it after the level:
+//
+//	INFO: greeting/greeter.go:274: Generating greeting for '[REDACTED]'
+//
+// Loggers with a slog.Handler pass the caller to it as the record's PC instead;
+// enable slog.HandlerOptions.AddSource to render it.
+//
+// The log.Lshortfile and log.Llongfile flags of the underlying log.Logger also
+// report the code that logged the message, whether or not WithCaller is set.
+func WithCaller() Option {
+	return func(l *ContextLogger) { l.addCaller = true }
+}
+
+// WithCallerSkip skips the given number of additional stack frames when
+// determining the caller of a message. Use it in helper functions that log on
+// behalf of their own caller, so that the helper's caller is reported instead.
+//
+// # Example
+//
+//	// logFailure reports the line that called it.
+//	var failureLogger = logger.NewContextLogger(nil, logger.WithCaller(), logger.WithCallerSkip(1))
+//
+//	func logFailure(ctx context.Context, err error) {
+//	    failureLogger.Error(ctx, "Operation failed: %v", err)
+//	}
+func WithCallerSkip(skip int) Option {
+	return func(l *ContextLogger) { l.callerSkip = max(skip, 0) }
+}
+
+// WithStackTrace makes the logger capture the stack of the calling goroutine for
+// messages of the given level and above, in Entry.Stack. The default is
+// LevelError, so that Error and Fatal messages carry a stack trace. JSONEncoder
+// and LogfmtEncoder write it as the "stack" field, and loggers with a
+// slog.Handler pass it as the "stack" attribute. TextEncoder leaves it out, so
+// the stack is not captured when every message is rendered with TextEncoder.
+func WithStackTrace(level Level) Option {
+	return func(l *ContextLogger) {
+		l.stackLevel = level
+		l.noStack = false
+	}
+}
+
+// WithoutStackTrace disables the capture of stack traces.
+func WithoutStackTrace() Option {
+	return func(l *ContextLogger) { l.noStack = true }
+}
+
+// needsCaller reports whether the caller of a message must be determined.
+func (l *ContextLogger) needsCaller(level Level) bool {
+	if l.addCaller || l.handler != nil || l.wantsStack(level) {
+		return true
+	}
+	if l.sinks != nil {
+		return slices.ContainsFunc(l.sinks, func(s *Sink) bool { return s.reportsFile() })
+	}
+	return l.logger.Flags()&(log.Lshortfile|log.Llongfile) != 0
+}
+
+// wantsStack reports whether messages of the given level carry a stack trace.
+// The stack is only captured if a handler or an encoder other than TextEncoder
+// can write it.
+func (l *ContextLogger) wantsStack(level Level) bool {
+	if l.noStack || level < l.stackLevel {
+		return false
+	}
+	if l.handler != nil {
+		return true
+	}
+	if l.sinks != nil {
+		return slices.ContainsFunc(l.sinks, func(s *Sink) bool { return writesStack(s.encoder) })
+	}
+	return writesStack(l.encoder)
+}
+
+// writesStack reports whether the encoder may write Entry.Stack. Only
+// TextEncoder is known to leave it out.
+func writesStack(encoder Encoder) bool {
+	_, text := encoder.(TextEncoder)
+	return !text
+}
+
+// callerPC returns the program counter of the frame skip levels above the
+// caller of callerPC, plus the logger's additional skip, or zero if the caller
+// is not needed.
+func (l *ContextLogger) callerPC(level Level, skip int) uintptr {
+	if !l.needsCaller(level) {
+		return 0
+	}
+	var pcs [1]uintptr
+	// Skip runtime.Callers, callerPC and the requested frames.
+	if runtime.Callers(skip+2+l.callerSkip, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
+// callers returns the program counters of the calling goroutine's stack, skipping
+// skip frames above the caller of callers.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth+16)
+	return pcs[:runtime.Callers(skip+2, pcs)]
+}
+
+// formatCaller renders the file and line of the frame with the given program
+// counter, keeping only the file's directory, e.g. "greeting/greeter.go:274".
+func formatCaller(pc uintptr) string {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if frame.File == "" {
+		return ""
+	}
+	file := frame.File
+	if i := strings.LastIndexByte(file, '/'); i >= 0 {
+		if j := strings.LastIndexByte(file[:i], '/'); j >= 0 {
+			file = file[j+1:]
+		}
+	}
+	return file + ":" + strconv.Itoa(frame.Line)
+}
+
+// formatStack renders a stack trace with one function per frame, followed by its
+// file and line on an indented line, like the traces of panics:
+//
+//	github.com/abitofhelp/bazel8_go/pkg/greeting.(*StandardGreeter).generate
+//		/src/pkg/greeting/greeter.go:294
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs[:min(len(pcs), maxStackDepth)])
+	for {
+		frame, more := frames.Next()
+		if b.Len() > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		if !more {
+			return b.String()
+		}
+	}
+}
+
+// completeCaller sets the caller and stack trace of an entry whose pc is known,
+// and returns the call depth of the caller relative to the caller of
+// completeCaller, as expected by log.Logger.Output, or zero if the caller is not
+// on the stack.
+func (l *ContextLogger) completeCaller(entry *Entry) int {
+	if entry.pc == 0 {
+		return 0
+	}
+	if l.addCaller {
+		entry.Caller = formatCaller(entry.pc)
+	}
+
+	// Skip callers and completeCaller.
+	pcs := callers(1)
+	i := slices.Index(pcs, entry.pc)
+	if i < 0 {
+		return 0
+	}
+	if l.wantsStack(entry.Level) {
+		entry.Stack = formatStack(pcs[i:])
+	}
+	// Output counts its caller as 1; pcs[0] is the caller of completeCaller.
+	return i + 1
+}
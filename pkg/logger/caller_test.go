@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// here returns the caller location of the line that calls it, as rendered in
+// Entry.Caller, with the line number adjusted by offset.
+func here(offset int) string {
+	_, file, line, _ := runtime.Caller(1)
+	return "logger/" + file[strings.LastIndexByte(file, '/')+1:] + ":" + fmt.Sprint(line+offset)
+}
+
+// logFailure is a helper that logs on behalf of its caller.
+func logFailure(l *ContextLogger, err error) {
+	l.Error(context.Background(), "Operation failed: %v", err)
+}
+
+func TestWithCaller(t *testing.T) {
+	var logOutput bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), WithCaller())
+	ctx := WithRequestID(context.Background(), "req-123")
+
+	ctxLogger.Info(ctx, "Processing")
+	want := here(-1)
+	ctxLogger.With(String("order", "ord-789")).WarningKV(ctx, "Slow", "items", 3)
+	wantKV := here(-1)
+
+	assert.Equal(t, "INFO: "+want+": [request_id=req-123] Processing\n"+
+		"WARNING: "+wantKV+": [request_id=req-123] Slow order=ord-789 items=3\n", logOutput.String())
+}
+
+func TestWithCallerSkip(t *testing.T) {
+	var logOutput bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), WithCaller(), WithCallerSkip(1), WithoutStackTrace())
+
+	logFailure(ctxLogger, fmt.Errorf("boom"))
+	assert.Equal(t, "ERROR: "+here(-1)+": Operation failed: boom\n", logOutput.String())
+}
+
+func TestCallerWithLogFlags(t *testing.T) {
+	var logOutput bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&logOutput, "", log.Lshortfile))
+
+	ctxLogger.Info(context.Background(), "Processing")
+	want := strings.TrimPrefix(here(-1), "logger/")
+	ctxLogger.ErrorKV(context.Background(), "Failed")
+	wantKV := strings.TrimPrefix(here(-1), "logger/")
+
+	assert.Equal(t, want+": INFO: Processing\n"+wantKV+": ERROR: Failed\n", logOutput.String(),
+		"log.Lshortfile should report the caller, not logger.go")
+}
+
+func TestStackTrace(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []Option
+		level     Level
+		wantStack bool
+	}{
+		{"Info by default", nil, LevelInfo, false},
+		{"Warning by default", nil, LevelWarning, false},
+		{"Error by default", nil, LevelError, true},
+		{"Warning with WithStackTrace", []Option{WithStackTrace(LevelWarning)}, LevelWarning, true},
+		{"Error with WithoutStackTrace", []Option{WithoutStackTrace()}, LevelError, false},
+		{"Error with WithStackTrace after WithoutStackTrace", []Option{WithoutStackTrace(), WithStackTrace(LevelError)}, LevelError, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logOutput bytes.Buffer
+			opts := append([]Option{WithEncoder(JSONEncoder{})}, tt.opts...)
+			ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), opts...)
+			switch tt.level {
+			case LevelInfo:
+				ctxLogger.Info(context.Background(), "Something happened")
+			case LevelWarning:
+				ctxLogger.Warning(context.Background(), "Something happened")
+			default:
+				ctxLogger.Error(context.Background(), "Something happened")
+			}
+
+			var decoded map[string]any
+			assert.NoError(t, json.Unmarshal(logOutput.Bytes(), &decoded))
+			stack, ok := decoded["stack"].(string)
+			assert.Equal(t, tt.wantStack, ok)
+			if tt.wantStack {
+				assert.True(t, strings.HasPrefix(stack, "github.com/abitofhelp/bazel8_go/pkg/logger.TestStackTrace.func1\n\t"),
+					"The stack should start at the caller: %s", stack)
+				assert.Contains(t, stack, "testing.tRunner")
+			}
+		})
+	}
+}
+
+func TestStackTraceOnlyForEncodersThatWriteIt(t *testing.T) {
+	assert.False(t, NewContextLogger(nil).wantsStack(LevelError), "TextEncoder leaves the stack out")
+	assert.True(t, NewContextLogger(nil, WithEncoder(LogfmtEncoder{})).wantsStack(LevelError))
+	assert.True(t, NewContextLogger(nil, WithSlogHandler(slog.NewTextHandler(io.Discard, nil))).wantsStack(LevelError))
+
+	text := NewSink("console", io.Discard, WithSinkSync())
+	jsonSink := NewSink("file", io.Discard, WithSinkSync(), WithSinkEncoder(JSONEncoder{}))
+	assert.False(t, NewContextLogger(nil, WithSinks(text)).wantsStack(LevelError))
+	assert.True(t, NewContextLogger(nil, WithSinks(text, jsonSink)).wantsStack(LevelError))
+}
+
+func TestCallerInStructuredEncoders(t *testing.T) {
+	var jsonOutput, logfmtOutput bytes.Buffer
+	jsonLogger := NewContextLogger(log.New(&jsonOutput, "", 0), WithEncoder(JSONEncoder{}), WithCaller())
+	logfmtLogger := NewContextLogger(log.New(&logfmtOutput, "", 0), WithEncoder(LogfmtEncoder{}), WithCaller())
+
+	jsonLogger.Error(context.Background(), "Failed")
+	wantJSON := here(-1)
+	logfmtLogger.Error(context.Background(), "Failed")
+	wantLogfmt := here(-1)
+
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal(jsonOutput.Bytes(), &decoded))
+	assert.Equal(t, wantJSON, decoded["caller"])
+	assert.Contains(t, decoded["stack"], "logger.TestCallerInStructuredEncoders")
+	assert.Regexp(t, `^\{"timestamp":"[^"]+","level":"ERROR","caller":"[^"]+","message":"Failed","stack":"`, jsonOutput.String())
+
+	assert.Regexp(t, `^time=\S+ level=ERROR caller=`+wantLogfmt+` msg=Failed stack="github.com/abitofhelp/bazel8_go/pkg/logger.TestCallerInStructuredEncoders\\n\\t`,
+		logfmtOutput.String())
+}
+
+func TestCallerWithSlog(t *testing.T) {
+	var jsonOutput bytes.Buffer
+	handler := slog.NewJSONHandler(&jsonOutput, &slog.HandlerOptions{AddSource: true})
+	ctxLogger := NewContextLogger(nil, WithSlogHandler(handler))
+
+	ctxLogger.Error(context.Background(), "Failed")
+	_, file, line, _ := runtime.Caller(0)
+
+	var decoded struct {
+		Source struct {
+			File string `json:"file"`
+			Line int    `json:"line"`
+		} `json:"source"`
+		Stack string `json:"stack"`
+	}
+	assert.NoError(t, json.Unmarshal(jsonOutput.Bytes(), &decoded))
+	assert.Equal(t, file, decoded.Source.File)
+	assert.Equal(t, line-1, decoded.Source.Line)
+	assert.Contains(t, decoded.Stack, "logger.TestCallerWithSlog")
+
+	var logOutput bytes.Buffer
+	slogLogger := slog.New(NewSlogHandler(NewContextLogger(log.New(&logOutput, "", 0), WithCaller())))
+	slogLogger.Info("Order shipped", "items", 3)
+	assert.Equal(t, "INFO: "+here(-1)+": Order shipped items=3\n", logOutput.String(),
+		"The caller of the slog.Logger should be reported")
+}
@@ -4,7 +4,7 @@
 //
 // This package offers a context-aware logging system that ensures all log messages
 // include relevant context information. It provides a ContextLogger type with methods
-// for logging at different severity levels (info, warning, error, fatal).
+// for logging at different severity levels (debug, info, warning, error, fatal).
 //
 // The package also provides a DefaultLogger singleton instance that can be used
 // throughout the application without needing to create a new logger instance.
@@ -13,40 +13,80 @@
 //
 // - Context-aware logging that automatically includes context information
 // - Multiple log levels for different types of messages
+// - Minimum level filtering that skips formatting of discarded messages
+// - Runtime-adjustable levels, shareable between loggers and exposable over HTTP
+// - Structured key/value fields, per logger (With) or per message (the *KV methods)
+// - Pluggable output encoders: text (the default), JSON and logfmt
+// - Interoperability with log/slog in both directions
+// - Asynchronous writing with a bounded queue and a drop policy
+// - Log files rotated by size or time, with backups and gzip compression
+// - Redaction of secrets and personal data, and a Sensitive type for such values
+// - Sampling of repeated messages, with summaries of the suppressed ones
+// - Caller file:line reporting and stack traces for Error and Fatal messages
+// - Exit hooks run by Fatal before exiting, and an injectable exit function
+// - Fan-out to several sinks with their own levels and encoders, isolated from each other's failures
 // - Support for formatted log messages (similar to fmt.Printf)
 // - Easy creation of custom loggers with different configurations
 // - Helper functions to add values to the context for logging
+// - W3C Trace Context propagation, with trace and span IDs in every message
+// - A registry of extractors for logging further context values
 //
 // # Log Levels
 //
 // The logger supports the following log levels, in order of increasing severity:
 //
-// 1. Info: For general information about application progress and normal operations.
-//    Use this for messages that are helpful for understanding what the application is doing.
-//    Example: "Processing item 123", "Request completed successfully"
+//  0. Debug: For detailed diagnostic information that is only needed when investigating a problem.
+//     Debug messages are discarded by default.
+//     Example: "Cache lookup for key user-456"
 //
-// 2. Warning: For unusual or unexpected situations that don't prevent the application
-//    from functioning but might indicate a problem.
-//    Example: "Unusual condition detected", "Using fallback method"
+//  1. Info: For general information about application progress and normal operations.
+//     Use this for messages that are helpful for understanding what the application is doing.
+//     Example: "Processing item 123", "Request completed successfully"
 //
-// 3. Error: For errors that prevent a specific operation from completing successfully
-//    but don't cause the application to stop.
-//    Example: "Failed to process item 123", "Database connection failed"
+//  2. Warning: For unusual or unexpected situations that don't prevent the application
+//     from functioning but might indicate a problem.
+//     Example: "Unusual condition detected", "Using fallback method"
 //
-// 4. Fatal: For critical errors that prevent the application from continuing.
-//    This level will terminate the application after logging the message.
-//    Example: "Configuration file not found", "Required service unavailable"
+//  3. Error: For errors that prevent a specific operation from completing successfully
+//     but don't cause the application to stop.
+//     Example: "Failed to process item 123", "Database connection failed"
+//
+//  4. Fatal: For critical errors that prevent the application from continuing.
+//     This level will terminate the application after logging the message and
+//     running the exit hooks (see below).
+//     Example: "Configuration file not found", "Required service unavailable"
+//
+// Each logger has a minimum level, LevelInfo unless configured otherwise with
+// WithLevel. Messages below the minimum level are discarded before they are
+// formatted, and Enabled reports whether a level is logged so that callers can
+// skip expensive work:
+//
+//	debugLogger := logger.NewContextLogger(nil, logger.WithLevel(logger.LevelDebug))
+//	if debugLogger.Enabled(ctx, logger.LevelDebug) {
+//	    debugLogger.Debug(ctx, "State: %s", dumpState())
+//	}
+//
+// Fatal messages are never discarded.
+//
+// The minimum level is held by an AtomicLevel, which can be changed while the
+// application is running and shared by several loggers. AtomicLevel implements
+// http.Handler, so it can be exposed on an admin endpoint that reads (GET) and
+// updates (PUT) the level as JSON:
+//
+//	http.Handle("/loglevel", logger.DefaultLogger.AtomicLevel())
+//	// curl -X PUT -d '{"level":"debug"}' http://localhost:6060/loglevel
 //
 // # Log Message Format
 //
 // Log messages follow this format:
 //
-//	LEVEL: [context_info] message
+//	LEVEL: [context_info] message key=value ...
 //
 // Where:
-// - LEVEL is the log level (INFO, WARNING, ERROR, FATAL)
+// - LEVEL is the log level (DEBUG, INFO, WARNING, ERROR, FATAL)
 // - context_info is information extracted from the context (if available)
 // - message is the actual log message
+// - key=value pairs are the structured fields of the message (if any)
 //
 // Example:
 //
@@ -75,6 +115,166 @@
 //	logger.DefaultLogger.Info(ctx, "Processing request")
 //	// Output: INFO: [request_id=req-123 user_id=user-456] Processing request
 //
+// A W3C trace context stored with WithTraceContext adds trace_id and span_id, so
+// that log lines can be correlated with the traces of other services.
+// ExtractTraceContext and InjectTraceContext read and write the traceparent and
+// tracestate headers:
+//
+//	if parent, err := logger.ExtractTraceContext(r.Header); err == nil {
+//	    ctx = logger.WithTraceContext(ctx, parent.Child())
+//	}
+//	logger.DefaultLogger.Info(ctx, "Handling request")
+//	// Output: INFO: [trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=53995c3f42cd8ad8] Handling request
+//
+// Other packages can have their own context values logged, such as a tenant or
+// session ID, by registering an Extractor. Registered values are logged in
+// registration order, after the request, user and trace IDs:
+//
+//	logger.RegisterExtractor(logger.Extractor{Name: "tenant_id", Key: tenantKey{}})
+//	// Output: INFO: [request_id=req-123 tenant_id=acme] Processing request
+//
+// # Structured Fields
+//
+// Values that should be machine-parsable are logged as fields rather than being
+// embedded in the message. With derives a child logger that includes fields in
+// every message, and the *KV methods (DebugKV, InfoKV, WarningKV, ErrorKV and
+// FatalKV) accept fields for a single message as alternating keys and values:
+//
+//	orderLogger := logger.DefaultLogger.With(logger.String("order", orderID))
+//	orderLogger.InfoKV(ctx, "Order shipped", "items", 3, logger.Duration("elapsed", elapsed))
+//	// Output: INFO: Order shipped order=ord-789 items=3 elapsed=1.5s
+//
+// Typed constructors such as String, Int, Bool, Duration, Time and Err create
+// fields explicitly. Values containing spaces, quotes or equals signs are quoted.
+//
+// # Output Encoders
+//
+// Messages are rendered by an Encoder. TextEncoder produces the format described
+// above and is the default. JSONEncoder writes one JSON object per line, with
+// timestamp, level, message, context values and fields as properly escaped members;
+// LogfmtEncoder writes logfmt key=value pairs. Because these encoders include their
+// own timestamp, use them with a log.Logger that has no prefix and flags:
+//
+//	jsonLogger := logger.NewContextLogger(log.New(os.Stdout, "", 0), logger.WithEncoder(logger.JSONEncoder{}))
+//	jsonLogger.InfoKV(ctx, "Order shipped", "items", 3)
+//	// Output: {"timestamp":"2025-01-02T03:04:05.678Z","level":"INFO","message":"Order shipped","request_id":"req-123","items":3}
+//
+// # Asynchronous Writing
+//
+// Logging writes to the underlying log.Logger synchronously, so a slow sink
+// slows down the caller. An AsyncWriter queues the messages and writes them in a
+// background goroutine instead. When its bounded queue is full, the DropPolicy
+// decides whether the newest or the oldest message is dropped (Dropped counts
+// them) or the caller blocks. Flush and Close wait, up to a context deadline,
+// until the queued messages have been written:
+//
+//	async := logger.NewAsyncWriter(os.Stderr, logger.WithDropPolicy(logger.DropOldest))
+//	ctxLogger := logger.NewContextLogger(log.New(async, "", log.LstdFlags))
+//	defer async.Close(shutdownCtx)
+//
+// # Log File Rotation
+//
+// A RotatingFile writes to a log file and rotates it when it would exceed a
+// maximum size (WithMaxSize) or at every multiple of an interval
+// (WithRotationInterval). Rotated files are renamed app.log.1, app.log.2 and so
+// on, optionally compressed in the background (WithCompression), and the oldest
+// beyond WithMaxBackups are deleted. Reopen supports external rotation by tools
+// such as logrotate, which signal the process with SIGHUP after moving the file:
+//
+//	file, err := logger.NewRotatingFile("/var/log/app.log", logger.WithMaxSize(10<<20), logger.WithCompression())
+//	ctxLogger := logger.NewContextLogger(log.New(file, "", log.LstdFlags))
+//
+// # Redaction
+//
+// Values that must not appear in logs can be wrapped in Sensitive, which always
+// renders as "[REDACTED]". A Redactor attached with WithRedactor removes sensitive
+// values that are logged unwrapped: its rules select them by regular expression
+// (RedactPattern), field name (RedactFields) or context key (RedactContextKeys),
+// and mask, hash or drop them. Hashing with RedactHash uses a keyed HMAC, so that
+// entries about the same user can still be joined:
+//
+//	redactor, err := logger.NewRedactor(
+//	    logger.RedactContextKeys(logger.RedactHash, "user_id"),
+//	    logger.WithHashKey(hashKey),
+//	)
+//	ctxLogger := logger.NewContextLogger(nil, logger.WithRedactor(redactor))
+//
+// Redaction is opt-in: DefaultLogger and loggers without a Redactor log context
+// values such as user_id verbatim.
+//
+// # Sampling
+//
+// A Sampler attached with WithSampler limits how many messages of the same level
+// and format string are written: in every interval, the first N and then every
+// Mth one. When an interval ends, the logger reports how many messages were
+// suppressed, from a timer if nothing else is logged; FlushSampling reports them
+// immediately:
+//
+//	ctxLogger := logger.NewContextLogger(nil, logger.WithSampler(logger.NewSampler(10, 100, time.Second)))
+//	defer ctxLogger.FlushSampling()
+//
+// # Caller and Stack Traces
+//
+// WithCaller records the file and line of the code that logged each message in
+// Entry.Caller, which the encoders render after the level; WithCallerSkip adjusts
+// it for helper functions. The log.Lshortfile and log.Llongfile flags of the
+// underlying log.Logger report the same location.
+//
+// Error and Fatal messages carry the stack trace of the logging goroutine in
+// Entry.Stack, which JSONEncoder and LogfmtEncoder write as the "stack" field.
+// TextEncoder leaves it out, so loggers that only use TextEncoder skip capturing it.
+// WithStackTrace changes the level from which stack traces are captured, and
+// WithoutStackTrace disables them:
+//
+//	ctxLogger := logger.NewContextLogger(log.New(os.Stdout, "", 0),
+//	    logger.WithEncoder(logger.JSONEncoder{}), logger.WithCaller())
+//	ctxLogger.Error(ctx, "Failed to ship order")
+//	// Output: {"timestamp":"...","level":"ERROR","caller":"shop/order.go:42","message":"Failed to ship order","stack":"main.shipOrder\n\t/src/shop/order.go:42\n..."}
+//
+// # Exit Hooks
+//
+// Fatal exits the program without running deferred calls. Cleanup that must
+// happen anyway, such as flushing an AsyncWriter or closing a RotatingFile, is
+// registered with RegisterExitHook; Fatal runs the hooks in reverse order of
+// registration, waiting at most DefaultExitTimeout (see WithExitTimeout), and
+// then calls os.Exit. WithExitFunc replaces os.Exit, so that Fatal can be tested:
+//
+//	defer logger.RegisterExitHook(func(ctx context.Context) { async.Close(ctx) })()
+//	testLogger := logger.NewContextLogger(stdLogger, logger.WithExitFunc(func(code int) { exitCode = code }))
+//
+// # Multiple Sinks
+//
+// WithSinks makes a logger write each message to several sinks instead of its
+// log.Logger, such as text on the console at Info, JSON in a file at Debug and
+// errors only on stderr. Every Sink has its own level, encoder and log flags,
+// and writes in the background, so that a slow sink holds up neither the caller
+// nor the other sinks (WithSinkSync opts out). A sink that fails or panics
+// doesn't keep the message from the others; its errors are counted and passed to
+// the WithSinkErrorHandler callback. Close sinks during shutdown; Fatal writes
+// the queued messages of the logger's sinks before exiting:
+//
+//	file := logger.NewSink("file", jsonFile, logger.WithSinkLevel(logger.LevelDebug),
+//	    logger.WithSinkEncoder(logger.JSONEncoder{}))
+//	console := logger.NewSink("console", os.Stdout)
+//	ctxLogger := logger.NewContextLogger(nil, logger.WithLevel(logger.LevelDebug),
+//	    logger.WithSinks(console, file))
+//	defer console.Close(shutdownCtx)
+//	defer file.Close(shutdownCtx)
+//
+// # Using log/slog
+//
+// NewSlogHandler returns an slog.Handler that writes through a ContextLogger, so
+// that code using log/slog shares the logger's level, encoder and output, and
+// gets the request and user IDs from the context:
+//
+//	slog.SetDefault(slog.New(logger.NewSlogHandler(logger.DefaultLogger)))
+//	slog.InfoContext(ctx, "Order shipped", "items", 3)
+//	// Output: INFO: [request_id=req-123] Order shipped items=3
+//
+// Conversely, WithSlogHandler makes a ContextLogger send its messages to any
+// slog.Handler, and NewContextHandler wraps an slog.Handler so that it adds the
+// context values as attributes.
+//
 // # Context Convention
 //
 // All logging methods require a context.Context as their first parameter, following
@@ -0,0 +1,261 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Entry is a single log message as passed to an Encoder.
+type Entry struct {
+	// Time is when the message was logged.
+	Time time.Time
+	// Level is the severity of the message.
+	Level Level
+	// Message is the formatted log message.
+	Message string
+	// Context holds the values extracted from the context, such as request_id and user_id.
+	Context []Field
+	// Fields holds the structured fields of the logger and of the message, in that order.
+	Fields []Field
+	// Caller is the file and line of the code that logged the message, such as
+	// "greeting/greeter.go:274", or empty unless the logger was created with WithCaller.
+	Caller string
+	// Stack is the stack trace of the goroutine that logged the message, starting
+	// at the caller, or empty if the level carries no stack trace (see WithStackTrace).
+	Stack string
+
+	// pc is the program counter of the caller, or zero if it was not determined.
+	pc uintptr
+}
+
+// Encoder renders log entries. The output of an encoder is written to the
+// logger's underlying log.Logger, which adds its prefix and flags and terminates
+// the line, so encoders must not write a trailing newline.
+//
+// The package provides TextEncoder (the default), JSONEncoder and LogfmtEncoder.
+// Encoders must be safe for concurrent use by multiple goroutines.
+type Encoder interface {
+	// Encode appends the rendered entry to buf.
+	Encode(buf *bytes.Buffer, e *Entry) error
+}
+
+// TextEncoder renders entries in the package's traditional human-readable format:
+//
+//	LEVEL: caller: [request_id=req-123 user_id=user-456] message key=value ...
+//
+// The caller is only rendered if it is known (see WithCaller). TextEncoder does
+// not render the entry's time and stack trace; use the flags of the underlying
+// log.Logger (such as log.LstdFlags) to prefix each line with a timestamp.
+type TextEncoder struct{}
+
+// Encode implements Encoder.
+func (TextEncoder) Encode(buf *bytes.Buffer, e *Entry) error {
+	buf.WriteString(e.Level.String())
+	buf.WriteString(": ")
+	if e.Caller != "" {
+		buf.WriteString(e.Caller)
+		buf.WriteString(": ")
+	}
+	if len(e.Context) > 0 {
+		buf.WriteByte('[')
+		for i, f := range e.Context {
+			if i > 0 {
+				buf.WriteByte(' ')
+			}
+			buf.WriteString(f.Key)
+			buf.WriteByte('=')
+			buf.WriteString(formatValue(f.Value))
+		}
+		buf.WriteString("] ")
+	}
+	buf.WriteString(e.Message)
+	for _, f := range e.Fields {
+		buf.WriteByte(' ')
+		appendKeyValue(buf, f.Key, f.Value)
+	}
+	return nil
+}
+
+// JSONEncoder renders each entry as a single-line JSON object, suitable for log
+// pipelines that ingest newline-delimited JSON:
+//
+//	{"timestamp":"2025-01-02T03:04:05.678Z","level":"INFO","message":"Order shipped","request_id":"req-123","order":"ord-789","items":3}
+//
+// Context values and fields are written as top-level members after the
+// timestamp, level, caller (if known) and message, followed by the stack trace
+// (if any). A context value or field named like one of these members is written
+// with a "fields." prefix, e.g. "fields.message", so that no key is duplicated.
+// Numbers and booleans keep their JSON types; errors, durations and values
+// implementing fmt.Stringer are written as strings, times in RFC 3339 format.
+// Values that cannot be represented in JSON are written as strings.
+//
+// Create the underlying log.Logger without prefix and flags, for example with
+// log.New(os.Stdout, "", 0), so that each line is a valid JSON document.
+type JSONEncoder struct{}
+
+// Encode implements Encoder.
+func (JSONEncoder) Encode(buf *bytes.Buffer, e *Entry) error {
+	buf.WriteString(`{"timestamp":`)
+	appendJSONString(buf, e.Time.Format(time.RFC3339Nano))
+	buf.WriteString(`,"level":`)
+	appendJSONString(buf, e.Level.String())
+	if e.Caller != "" {
+		buf.WriteString(`,"caller":`)
+		appendJSONString(buf, e.Caller)
+	}
+	buf.WriteString(`,"message":`)
+	appendJSONString(buf, e.Message)
+	for _, fields := range [][]Field{e.Context, e.Fields} {
+		for _, f := range fields {
+			buf.WriteByte(',')
+			appendJSONString(buf, jsonFieldKey(f.Key))
+			buf.WriteByte(':')
+			appendJSONValue(buf, f.Value)
+		}
+	}
+	if e.Stack != "" {
+		buf.WriteString(`,"stack":`)
+		appendJSONString(buf, e.Stack)
+	}
+	buf.WriteByte('}')
+	return nil
+}
+
+// jsonReservedKeys are the members that JSONEncoder writes itself.
+var jsonReservedKeys = map[string]bool{
+	"timestamp": true,
+	"level":     true,
+	"caller":    true,
+	"message":   true,
+	"stack":     true,
+}
+
+// jsonFieldKey returns the key under which JSONEncoder writes a context value or
+// field, prefixing keys that would collide with the members it writes itself.
+func jsonFieldKey(key string) string {
+	if jsonReservedKeys[key] {
+		return "fields." + key
+	}
+	return key
+}
+
+// LogfmtEncoder renders each entry as a line of logfmt key=value pairs:
+//
+//	time=2025-01-02T03:04:05.678Z level=INFO msg="Order shipped" request_id=req-123 order=ord-789 items=3
+//
+// Values containing spaces, quotes, equals signs or control characters are quoted.
+// As with JSONEncoder, the caller follows the level and the stack trace comes
+// last, if they are known. Create the underlying log.Logger without prefix and flags.
+type LogfmtEncoder struct{}
+
+// Encode implements Encoder.
+func (LogfmtEncoder) Encode(buf *bytes.Buffer, e *Entry) error {
+	appendKeyValue(buf, "time", e.Time)
+	buf.WriteByte(' ')
+	appendKeyValue(buf, "level", e.Level)
+	if e.Caller != "" {
+		buf.WriteByte(' ')
+		appendKeyValue(buf, "caller", e.Caller)
+	}
+	buf.WriteByte(' ')
+	appendKeyValue(buf, "msg", e.Message)
+	for _, fields := range [][]Field{e.Context, e.Fields} {
+		for _, f := range fields {
+			buf.WriteByte(' ')
+			appendKeyValue(buf, f.Key, f.Value)
+		}
+	}
+	if e.Stack != "" {
+		buf.WriteByte(' ')
+		appendKeyValue(buf, "stack", e.Stack)
+	}
+	return nil
+}
+
+// appendKeyValue writes key=value to buf, quoting the key and value if needed.
+func appendKeyValue(buf *bytes.Buffer, key string, value any) {
+	buf.WriteString(quoteIfNeeded(key))
+	buf.WriteByte('=')
+	buf.WriteString(quoteIfNeeded(formatValue(value)))
+}
+
+// hexDigits is used to escape control characters in JSON strings.
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString writes s to buf as a JSON string. Unlike encoding/json, it
+// does not escape HTML characters, which keeps messages readable. Invalid UTF-8
+// is replaced with U+FFFD.
+func appendJSONString(buf *bytes.Buffer, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			buf.WriteByte('\\')
+			buf.WriteRune(r)
+		case r == '\n':
+			buf.WriteString(`\n`)
+		case r == '\r':
+			buf.WriteString(`\r`)
+		case r == '\t':
+			buf.WriteString(`\t`)
+		case r < 0x20 || r == '\u2028' || r == '\u2029':
+			buf.WriteString(`\u`)
+			buf.WriteByte(hexDigits[r>>12&0xf])
+			buf.WriteByte(hexDigits[r>>8&0xf])
+			buf.WriteByte(hexDigits[r>>4&0xf])
+			buf.WriteByte(hexDigits[r&0xf])
+		default:
+			// Ranging over a string yields utf8.RuneError for invalid bytes.
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+}
+
+// appendJSONValue writes v to buf as a JSON value. Values without a natural JSON
+// representation are written as strings.
+func appendJSONValue(buf *bytes.Buffer, v any) {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case string:
+		appendJSONString(buf, v)
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case int:
+		buf.WriteString(strconv.Itoa(v))
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case uint64:
+		buf.WriteString(strconv.FormatUint(v, 10))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			appendJSONString(buf, strconv.FormatFloat(v, 'g', -1, 64))
+			return
+		}
+		buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	case []byte, error, time.Time, time.Duration:
+		appendJSONString(buf, formatValue(v))
+	case json.Marshaler:
+		appendJSONMarshal(buf, v)
+	case fmt.Stringer:
+		appendJSONString(buf, formatValue(v))
+	default:
+		appendJSONMarshal(buf, v)
+	}
+}
+
+// appendJSONMarshal writes v to buf using encoding/json, falling back to a string
+// if v cannot be marshaled.
+func appendJSONMarshal(buf *bytes.Buffer, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		appendJSONString(buf, formatValue(v))
+		return
+	}
+	buf.Write(b)
+}
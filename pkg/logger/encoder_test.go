@@ -0,0 +1,147 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testEntry returns an entry that exercises context values and typed fields.
+func testEntry() *Entry {
+	return &Entry{
+		Time:    time.Date(2025, 1, 2, 3, 4, 5, 678000000, time.UTC),
+		Level:   LevelInfo,
+		Message: `Order "ord-789" shipped`,
+		Context: []Field{String("request_id", "req-123"), String("user_id", "user-456")},
+		Fields: []Field{
+			String("order", "ord-789"),
+			Int("items", 3),
+			Float64("weight", 1.25),
+			Bool("express", true),
+			Duration("elapsed", 1500*time.Millisecond),
+			Err(errors.New("carrier <unavailable>")),
+			Any("tags", []string{"a", "b"}),
+			Any("missing", nil),
+		},
+	}
+}
+
+func TestTextEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, TextEncoder{}.Encode(&buf, testEntry()))
+	assert.Equal(t, `INFO: [request_id=req-123 user_id=user-456] Order "ord-789" shipped order=ord-789 items=3 weight=1.25 express=true elapsed=1.5s error="carrier <unavailable>" tags="[a b]" missing=<nil>`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, TextEncoder{}.Encode(&buf, &Entry{Level: LevelWarning, Message: "plain"}))
+	assert.Equal(t, "WARNING: plain", buf.String())
+}
+
+func TestJSONEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, JSONEncoder{}.Encode(&buf, testEntry()))
+	assert.Equal(t, `{"timestamp":"2025-01-02T03:04:05.678Z","level":"INFO","message":"Order \"ord-789\" shipped","request_id":"req-123","user_id":"user-456","order":"ord-789","items":3,"weight":1.25,"express":true,"elapsed":"1.5s","error":"carrier <unavailable>","tags":["a","b"],"missing":null}`, buf.String())
+
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &decoded), "Output should be valid JSON")
+	assert.Equal(t, "carrier <unavailable>", decoded["error"])
+}
+
+func TestJSONEncoderReservedKeys(t *testing.T) {
+	var buf bytes.Buffer
+	entry := &Entry{
+		Time:    time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+		Level:   LevelWarning,
+		Message: "Retrying",
+		Context: []Field{String("level", "gold")},
+		Fields:  []Field{String("message", "original"), Int("timestamp", 1), String("caller", "job"), String("stack", "s")},
+	}
+	assert.NoError(t, JSONEncoder{}.Encode(&buf, entry))
+	assert.Equal(t, `{"timestamp":"2025-01-02T03:04:05Z","level":"WARNING","message":"Retrying","fields.level":"gold",`+
+		`"fields.message":"original","fields.timestamp":1,"fields.caller":"job","fields.stack":"s"}`, buf.String())
+}
+
+func TestJSONEncoderValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{name: "int64", value: int64(-7), expected: `-7`},
+		{name: "uint64", value: uint64(math.MaxUint64), expected: `18446744073709551615`},
+		{name: "NaN", value: math.NaN(), expected: `"NaN"`},
+		{name: "infinity", value: math.Inf(1), expected: `"+Inf"`},
+		{name: "time", value: time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), expected: `"2025-01-02T00:00:00Z"`},
+		{name: "text marshaler", value: LevelDebug, expected: `"DEBUG"`},
+		{name: "map", value: map[string]int{"a": 1}, expected: `{"a":1}`},
+		{name: "unsupported", value: make(chan int), expected: `"0x`},
+		{name: "control characters", value: "line\nbreak\ttab\x01", expected: `"line\nbreak\ttab\u0001"`},
+		{name: "quotes and backslashes", value: `say "hi" \o/`, expected: `"say \"hi\" \\o/"`},
+		{name: "invalid UTF-8", value: "bad\xffbyte", expected: "\"bad\ufffdbyte\""},
+		{name: "HTML", value: "<b>&</b>", expected: `"<b>&</b>"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			appendJSONValue(&buf, tt.value)
+			assert.True(t, strings.HasPrefix(buf.String(), tt.expected), "Expected %s, got %s", tt.expected, buf.String())
+		})
+	}
+}
+
+func TestLogfmtEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, LogfmtEncoder{}.Encode(&buf, testEntry()))
+	assert.Equal(t, `time=2025-01-02T03:04:05.678Z level=INFO msg="Order \"ord-789\" shipped" request_id=req-123 user_id=user-456 order=ord-789 items=3 weight=1.25 express=true elapsed=1.5s error="carrier <unavailable>" tags="[a b]" missing=<nil>`, buf.String())
+}
+
+func TestContextLoggerEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&buf, "", 0), WithEncoder(JSONEncoder{})).With(String("service", "greeter"))
+	ctx := WithRequestID(context.Background(), "req-123")
+
+	ctxLogger.InfoKV(ctx, "Order shipped", "items", 3)
+	ctxLogger.Warning(ctx, "Disk %d%% full", 85)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		var first, second map[string]any
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+		assert.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+
+		assert.Equal(t, "INFO", first["level"])
+		assert.Equal(t, "Order shipped", first["message"])
+		assert.Equal(t, "req-123", first["request_id"])
+		assert.Equal(t, "greeter", first["service"])
+		assert.Equal(t, float64(3), first["items"])
+		_, err := time.Parse(time.RFC3339Nano, first["timestamp"].(string))
+		assert.NoError(t, err)
+
+		assert.Equal(t, "WARNING", second["level"])
+		assert.Equal(t, "Disk 85% full", second["message"])
+	}
+}
+
+// failingEncoder is an Encoder that always fails after writing partial output.
+type failingEncoder struct{}
+
+func (failingEncoder) Encode(buf *bytes.Buffer, e *Entry) error {
+	buf.WriteString("partial")
+	return errors.New("boom")
+}
+
+func TestContextLoggerEncoderFailure(t *testing.T) {
+	var buf bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&buf, "", 0), WithEncoder(failingEncoder{}))
+
+	ctxLogger.Error(context.Background(), "test error message")
+	assert.Equal(t, "ERROR: test error message (log encoding failed: boom)\n", buf.String())
+}
@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultExitTimeout bounds how long Fatal waits for the exit hooks unless the
+// logger is configured otherwise with WithExitTimeout.
+const DefaultExitTimeout = 5 * time.Second
+
+// exitHook wraps a hook function, so that it can be found again to be removed.
+type exitHook struct {
+	run func(ctx context.Context)
+}
+
+var (
+	// exitHooksMu guards exitHooks.
+	exitHooksMu sync.Mutex
+	// exitHooks holds the registered exit hooks in registration order.
+	exitHooks []*exitHook
+)
+
+// WithExitFunc sets the function that Fatal and FatalKV call to exit the program
+// after the exit hooks have run. The default is os.Exit. Tests can record the
+// exit code instead of exiting, in which case Fatal returns.
+//
+// # Example
+//
+//	var code int
+//	ctxLogger := logger.NewContextLogger(stdLogger, logger.WithExitFunc(func(c int) { code = c }))
+//	ctxLogger.Fatal(ctx, "Configuration file not found")
+//	// code == 1
+func WithExitFunc(exit func(code int)) Option {
+	return func(l *ContextLogger) {
+		if exit != nil {
+			l.exit = exit
+		}
+	}
+}
+
+// WithExitTimeout sets how long Fatal and FatalKV wait for the exit hooks before
+// exiting anyway. The default is DefaultExitTimeout.
+func WithExitTimeout(d time.Duration) Option {
+	return func(l *ContextLogger) {
+		if d > 0 {
+			l.exitTimeout = d
+		}
+	}
+}
+
+// RegisterExitHook registers a function that is run before the program exits
+// because of a fatal message, such as one that flushes buffered log messages,
+// closes files or cancels contexts. Hooks run one at a time, in reverse order of
+// registration like deferred calls, and should return when ctx is done.
+//
+// # Parameters
+//
+// - hook: The function to run. Its context is canceled when the exit timeout has elapsed.
+//
+// # Return Value
+//
+// - unregister: A function that removes the hook again; it may be called more than once.
+//
+// # Example
+//
+//	async := logger.NewAsyncWriter(os.Stderr)
+//	unregister := logger.RegisterExitHook(func(ctx context.Context) { async.Close(ctx) })
+//	defer unregister()
+func RegisterExitHook(hook func(ctx context.Context)) (unregister func()) {
+	h := &exitHook{run: hook}
+	exitHooksMu.Lock()
+	exitHooks = append(exitHooks, h)
+	exitHooksMu.Unlock()
+
+	return func() {
+		exitHooksMu.Lock()
+		defer exitHooksMu.Unlock()
+		if i := slices.Index(exitHooks, h); i >= 0 {
+			exitHooks = slices.Delete(exitHooks, i, i+1)
+		}
+	}
+}
+
+// RunExitHooks runs the registered exit hooks in reverse order of registration
+// and waits until they have returned or ctx is done. Fatal calls it before
+// exiting; call it yourself before exiting the program in other ways. A hook
+// that panics does not keep the others from running.
+//
+// # Return Value
+//
+// - error: ctx.Err() if the context ended before all hooks returned, otherwise nil.
+func RunExitHooks(ctx context.Context) error {
+	exitHooksMu.Lock()
+	hooks := slices.Clone(exitHooks)
+	exitHooksMu.Unlock()
+
+	// completed is set before done is closed, if every hook has run.
+	var completed bool
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if ctx.Err() != nil {
+				return
+			}
+			runExitHook(ctx, hooks[i])
+		}
+		completed = true
+	}()
+
+	select {
+	case <-done:
+		if !completed {
+			return ctx.Err()
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// runExitHook runs a hook, recovering from a panic.
+func runExitHook(ctx context.Context, h *exitHook) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "logger: exit hook panicked: %v\n", r)
+		}
+	}()
+	h.run(ctx)
+}
+
+// exitProgram runs the exit hooks and writes the queued messages of the
+// logger's sinks, which the hooks may have added to, waiting at most the
+// logger's exit timeout, and calls the logger's exit function with the given code.
+func (l *ContextLogger) exitProgram(code int) {
+	ctx, cancel := context.WithTimeout(context.Background(), l.exitTimeout)
+	defer cancel()
+	if err := RunExitHooks(ctx); err != nil {
+		// The logger's output may be one of the resources the hooks failed to flush.
+		fmt.Fprintf(os.Stderr, "logger: exit hooks did not finish within %v\n", l.exitTimeout)
+	}
+	for _, s := range l.sinks {
+		if err := s.flush(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "logger: sink %s was not flushed within %v\n", s.name, l.exitTimeout)
+		}
+	}
+	l.exit(code)
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFatalRunsExitHooks(t *testing.T) {
+	var calls []string
+	defer RegisterExitHook(func(ctx context.Context) { calls = append(calls, "close file") })()
+	defer RegisterExitHook(func(ctx context.Context) { calls = append(calls, "flush") })()
+	unregister := RegisterExitHook(func(ctx context.Context) { calls = append(calls, "removed") })
+	unregister()
+	unregister()
+
+	var logOutput bytes.Buffer
+	exitCode := -1
+	ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), WithExitFunc(func(code int) {
+		calls = append(calls, "exit")
+		exitCode = code
+	}))
+
+	ctxLogger.FatalKV(WithRequestID(context.Background(), "req-123"), "Configuration file not found", "path", "/etc/app.json")
+	assert.Equal(t, "FATAL: [request_id=req-123] Configuration file not found path=/etc/app.json\n", logOutput.String())
+	assert.Equal(t, []string{"flush", "close file", "exit"}, calls, "Hooks should run in reverse order before exiting")
+	assert.Equal(t, 1, exitCode)
+}
+
+func TestFatalFlushesAsyncWriter(t *testing.T) {
+	w := newGatedWriter()
+	async := NewAsyncWriter(w)
+	defer RegisterExitHook(func(ctx context.Context) { async.Close(ctx) })()
+
+	exited := false
+	ctxLogger := NewContextLogger(log.New(async, "", 0), WithExitFunc(func(int) { exited = true }))
+	close(w.gate)
+	ctxLogger.Fatal(context.Background(), "Out of memory")
+
+	assert.True(t, exited)
+	assert.Equal(t, "FATAL: Out of memory\n", w.String(), "The fatal message should be written before exiting")
+}
+
+func TestFatalExitTimeout(t *testing.T) {
+	var canceled bool
+	block := make(chan struct{})
+	defer close(block)
+	defer RegisterExitHook(func(ctx context.Context) {
+		<-ctx.Done()
+		canceled = true
+	})()
+	defer RegisterExitHook(func(ctx context.Context) { <-block })()
+
+	exited := make(chan int, 1)
+	ctxLogger := NewContextLogger(log.New(&bytes.Buffer{}, "", 0),
+		WithExitTimeout(10*time.Millisecond), WithExitFunc(func(code int) { exited <- code }))
+
+	start := time.Now()
+	ctxLogger.Fatal(context.Background(), "Stuck")
+	assert.Equal(t, 1, <-exited)
+	assert.Less(t, time.Since(start), time.Second, "Fatal should not wait for hooks beyond the timeout")
+	assert.False(t, canceled, "Hooks after a stuck one should not run")
+}
+
+func TestRunExitHooks(t *testing.T) {
+	var ran bool
+	defer RegisterExitHook(func(ctx context.Context) { ran = true })()
+	defer RegisterExitHook(func(ctx context.Context) { panic("boom") })()
+
+	assert.NoError(t, RunExitHooks(context.Background()))
+	assert.True(t, ran, "A panicking hook should not keep the others from running")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	ran = false
+	assert.ErrorIs(t, RunExitHooks(ctx), context.Canceled)
+	assert.False(t, ran)
+}
+
+func TestExitOptionDefaults(t *testing.T) {
+	ctxLogger := NewContextLogger(nil, WithExitFunc(nil), WithExitTimeout(0))
+	assert.NotNil(t, ctxLogger.exit)
+	assert.Equal(t, DefaultExitTimeout, ctxLogger.exitTimeout)
+}
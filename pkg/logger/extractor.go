@@ -0,0 +1,154 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Extractor describes a value that is extracted from the context and included in
+// every log message, such as the request ID. Register extractors with
+// RegisterExtractor.
+type Extractor struct {
+	// Name is the key under which the value is logged, e.g. "tenant_id". It must
+	// be unique among the registered extractors.
+	Name string
+	// Key is the context key under which the value is stored.
+	Key any
+	// Format renders the value found in the context and reports whether it should
+	// be logged. If Format is nil, strings are logged unless they are empty, and
+	// other non-nil values are rendered like field values.
+	Format func(value any) (string, bool)
+}
+
+// extract returns the field for the value of e in ctx, and whether there is one.
+func (e Extractor) extract(ctx context.Context) (Field, bool) {
+	value := ctx.Value(e.Key)
+	if value == nil {
+		return Field{}, false
+	}
+	format := e.Format
+	if format == nil {
+		format = formatContextValue
+	}
+	s, ok := format(value)
+	if !ok {
+		return Field{}, false
+	}
+	return String(e.Name, s), true
+}
+
+// formatContextValue is the formatter of extractors that do not define one.
+func formatContextValue(value any) (string, bool) {
+	s := formatValue(value)
+	return s, s != ""
+}
+
+// formatNonEmptyString is the formatter of the built-in request_id and user_id
+// extractors, which log only non-empty strings.
+func formatNonEmptyString(value any) (string, bool) {
+	s, ok := value.(string)
+	return s, ok && s != ""
+}
+
+var (
+	// extractorsMu serializes changes to the registry.
+	extractorsMu sync.Mutex
+	// extractors holds the registered extractors in registration order. The slice
+	// is replaced, never modified, so that loggers can read it without locking.
+	extractors atomic.Pointer[[]Extractor]
+)
+
+func init() {
+	extractors.Store(&[]Extractor{
+		{Name: "request_id", Key: RequestIDKey, Format: formatNonEmptyString},
+		{Name: "user_id", Key: UserIDKey, Format: formatNonEmptyString},
+		{Name: "trace_id", Key: TraceContextKey, Format: formatTraceID},
+		{Name: "span_id", Key: TraceContextKey, Format: formatSpanID},
+	})
+}
+
+// RegisterExtractor adds an extractor to the registry, so that its value is
+// included in every log message whose context contains it. Values are logged in
+// registration order, after the built-in request_id, user_id, trace_id and span_id.
+//
+// Packages typically register their extractors in an init function, next to the
+// helper that stores the value in the context.
+//
+// # Parameters
+//
+// - e: The extractor to register. Its Name must be unique and not empty, and its Key must not be nil.
+//
+// # Return Value
+//
+// - error: An error if the extractor is invalid or its name is already registered.
+//
+// # Example
+//
+//	type tenantKey struct{}
+//
+//	func init() {
+//	    if err := logger.RegisterExtractor(logger.Extractor{Name: "tenant_id", Key: tenantKey{}}); err != nil {
+//	        panic(err)
+//	    }
+//	}
+//
+//	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+//	logger.DefaultLogger.Info(ctx, "Processing request")
+//	// Output: INFO: [request_id=req-123 tenant_id=acme] Processing request
+func RegisterExtractor(e Extractor) error {
+	if e.Name == "" {
+		return fmt.Errorf("logger: extractor name must not be empty")
+	}
+	if e.Key == nil {
+		return fmt.Errorf("logger: extractor %q has no context key", e.Name)
+	}
+
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	current := *extractors.Load()
+	if slices.ContainsFunc(current, func(x Extractor) bool { return x.Name == e.Name }) {
+		return fmt.Errorf("logger: extractor %q is already registered", e.Name)
+	}
+	updated := append(slices.Clip(current), e)
+	extractors.Store(&updated)
+	return nil
+}
+
+// UnregisterExtractor removes the extractor with the given name from the registry
+// and reports whether it was registered. It is mainly useful in tests.
+func UnregisterExtractor(name string) bool {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	current := *extractors.Load()
+	i := slices.IndexFunc(current, func(x Extractor) bool { return x.Name == name })
+	if i < 0 {
+		return false
+	}
+	updated := slices.Delete(slices.Clone(current), i, i+1)
+	extractors.Store(&updated)
+	return true
+}
+
+// Extractors returns the registered extractors in the order in which their values
+// are logged.
+func Extractors() []Extractor {
+	return slices.Clone(*extractors.Load())
+}
+
+// contextFields extracts the values of the registered extractors from the context.
+func contextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+
+	var fields []Field
+	for _, e := range *extractors.Load() {
+		if f, ok := e.extract(ctx); ok {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
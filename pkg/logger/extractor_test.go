@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tenantKey struct{}
+
+type sessionKey struct{}
+
+func TestRegisterExtractor(t *testing.T) {
+	assert.NoError(t, RegisterExtractor(Extractor{Name: "tenant_id", Key: tenantKey{}}))
+	t.Cleanup(func() { UnregisterExtractor("tenant_id") })
+	assert.NoError(t, RegisterExtractor(Extractor{
+		Name: "session_id",
+		Key:  sessionKey{},
+		Format: func(value any) (string, bool) {
+			id, ok := value.(int)
+			return "s" + strconv.Itoa(id), ok && id > 0
+		},
+	}))
+	t.Cleanup(func() { UnregisterExtractor("session_id") })
+
+	var names []string
+	for _, e := range Extractors() {
+		names = append(names, e.Name)
+	}
+	assert.Equal(t, []string{"request_id", "user_id", "trace_id", "span_id", "tenant_id", "session_id"}, names)
+
+	// Values are logged in registration order, regardless of the order in which
+	// they were added to the context.
+	ctx := context.WithValue(context.Background(), sessionKey{}, 42)
+	ctx = context.WithValue(ctx, tenantKey{}, "acme")
+	ctx = WithRequestID(ctx, "req-123")
+	assert.Equal(t, []Field{String("request_id", "req-123"), String("tenant_id", "acme"), String("session_id", "s42")},
+		contextFields(ctx))
+
+	var buf bytes.Buffer
+	NewContextLogger(log.New(&buf, "", 0)).Info(ctx, "Processing request")
+	assert.Equal(t, "INFO: [request_id=req-123 tenant_id=acme session_id=s42] Processing request\n", buf.String())
+
+	// Format can reject values, and empty strings are skipped by default.
+	ctx = context.WithValue(context.Background(), sessionKey{}, 0)
+	ctx = context.WithValue(ctx, tenantKey{}, "")
+	assert.Empty(t, contextFields(ctx))
+}
+
+func TestBuiltInExtractorsLogOnlyNonEmptyStrings(t *testing.T) {
+	var logOutput bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&logOutput, "", 0))
+
+	ctx := context.WithValue(WithRequestID(context.Background(), ""), UserIDKey, 456)
+	ctxLogger.Info(ctx, "Processing")
+	assert.Equal(t, "INFO: Processing\n", logOutput.String(),
+		"Empty and non-string request and user IDs should not be logged")
+}
+
+func TestRegisterExtractorErrors(t *testing.T) {
+	assert.EqualError(t, RegisterExtractor(Extractor{Key: tenantKey{}}),
+		"logger: extractor name must not be empty")
+	assert.EqualError(t, RegisterExtractor(Extractor{Name: "tenant_id"}),
+		`logger: extractor "tenant_id" has no context key`)
+	assert.EqualError(t, RegisterExtractor(Extractor{Name: "request_id", Key: tenantKey{}}),
+		`logger: extractor "request_id" is already registered`)
+}
+
+func TestUnregisterExtractor(t *testing.T) {
+	assert.NoError(t, RegisterExtractor(Extractor{Name: "tenant_id", Key: tenantKey{}}))
+	snapshot := Extractors()
+
+	assert.True(t, UnregisterExtractor("tenant_id"))
+	assert.False(t, UnregisterExtractor("tenant_id"))
+	assert.Len(t, Extractors(), len(snapshot)-1)
+	assert.Equal(t, "tenant_id", snapshot[len(snapshot)-1].Name, "Snapshots are not affected by later changes")
+
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	assert.Empty(t, contextFields(ctx))
+}
+
+func TestExtractorsConcurrentUse(t *testing.T) {
+	var buf bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&buf, "", 0))
+	ctx := context.WithValue(WithRequestID(context.Background(), "req-123"), tenantKey{}, "acme")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ctxLogger.Info(ctx, "Processing request")
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if RegisterExtractor(Extractor{Name: "tenant_id", Key: tenantKey{}}) == nil {
+					UnregisterExtractor("tenant_id")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	UnregisterExtractor("tenant_id")
+	assert.Len(t, Extractors(), 4)
+}
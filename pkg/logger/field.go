@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode"
+)
+
+// Field is a key/value pair attached to a log message. Fields make log messages
+// machine-parsable: instead of embedding values in the message text, they are
+// written as key=value pairs after the message.
+//
+// Create fields with the typed constructors, such as String, Int or Err, and
+// attach them to a logger with With or to a single message with the *KV methods.
+type Field struct {
+	// Key is the name of the field.
+	Key string
+	// Value is the value of the field.
+	Value any
+}
+
+// String returns a Field with a string value.
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int returns a Field with an int value.
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int64 returns a Field with an int64 value.
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Uint64 returns a Field with a uint64 value.
+func Uint64(key string, value uint64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Float64 returns a Field with a float64 value.
+func Float64(key string, value float64) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Bool returns a Field with a bool value.
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Duration returns a Field with a time.Duration value, rendered like "1.5s".
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Time returns a Field with a time.Time value, rendered in RFC 3339 format.
+func Time(key string, value time.Time) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err returns a Field named "error" holding err.
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// Any returns a Field with an arbitrary value, rendered with fmt.Sprint unless it
+// is one of the types supported by the other constructors.
+func Any(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// badKey is the key of a value that is not preceded by a key in a list of
+// alternating keys and values.
+const badKey = "!BADKEY"
+
+// fieldsFromKV converts a list of alternating keys and values, as accepted by the
+// *KV methods, to fields. Field elements are used as they are. A value without a
+// string key is kept under the key "!BADKEY" so that it is not lost.
+func fieldsFromKV(keysAndValues []any) []Field {
+	fields := make([]Field, 0, len(keysAndValues)/2)
+	for i := 0; i < len(keysAndValues); i++ {
+		switch k := keysAndValues[i].(type) {
+		case Field:
+			fields = append(fields, k)
+		case string:
+			if i+1 == len(keysAndValues) {
+				fields = append(fields, Field{Key: badKey, Value: k})
+				continue
+			}
+			fields = append(fields, Field{Key: k, Value: keysAndValues[i+1]})
+			i++
+		default:
+			fields = append(fields, Field{Key: badKey, Value: k})
+		}
+	}
+	return fields
+}
+
+// formatValue renders a field value as text. Byte slices are rendered as the
+// text they hold. Errors and fmt.Stringer values are rendered by fmt, which turns
+// a nil receiver or a panic of their method into text instead of panicking.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// quoteIfNeeded quotes s if it is empty or contains spaces, quotes, equals signs
+// or non-printable characters, so that key=value pairs remain unambiguous.
+func quoteIfNeeded(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
+// With returns a child logger that includes the given fields in every message,
+// after any fields of l. The child shares l's output and minimum level; l itself
+// is not modified.
+//
+// # Example
+//
+//	orderLogger := logger.DefaultLogger.With(logger.String("order", orderID))
+//	orderLogger.Info(ctx, "Order shipped")
+//	// Output: INFO: Order shipped order=ord-789
+func (l *ContextLogger) With(fields ...Field) *ContextLogger {
+	child := *l
+	child.fields = append(l.fields[:len(l.fields):len(l.fields)], fields...)
+	return &child
+}
+
+// logKV writes a message with fields given as alternating keys and values,
+// unless the level is disabled or the message is sampled out.
+func (l *ContextLogger) logKV(ctx context.Context, level Level, msg string, keysAndValues []any) {
+	if !l.Enabled(ctx, level) || !l.sampled(level, msg) {
+		return
+	}
+	l.write(ctx, level, msg, fieldsFromKV(keysAndValues))
+}
+
+// DebugKV logs a debug message with structured fields.
+//
+// The keysAndValues are alternating keys and values, such as "order", id, "items", 3.
+// Field values, such as logger.Int("items", 3), can be mixed in and stand on their own.
+// Keys must be strings; a value without a key is logged under the key "!BADKEY".
+//
+// # Example
+//
+//	logger.DefaultLogger.DebugKV(ctx, "Cache lookup", "key", key, logger.Bool("hit", hit))
+//	// Output: DEBUG: Cache lookup key=user-456 hit=true
+func (l *ContextLogger) DebugKV(ctx context.Context, msg string, keysAndValues ...any) {
+	l.logKV(ctx, LevelDebug, msg, keysAndValues)
+}
+
+// InfoKV logs an informational message with structured fields. See DebugKV for
+// the format of keysAndValues.
+//
+// # Example
+//
+//	logger.DefaultLogger.InfoKV(ctx, "Order shipped", "order", orderID, "items", 3)
+//	// Output: INFO: Order shipped order=ord-789 items=3
+func (l *ContextLogger) InfoKV(ctx context.Context, msg string, keysAndValues ...any) {
+	l.logKV(ctx, LevelInfo, msg, keysAndValues)
+}
+
+// WarningKV logs a warning message with structured fields. See DebugKV for the
+// format of keysAndValues.
+func (l *ContextLogger) WarningKV(ctx context.Context, msg string, keysAndValues ...any) {
+	l.logKV(ctx, LevelWarning, msg, keysAndValues)
+}
+
+// ErrorKV logs an error message with structured fields. See DebugKV for the
+// format of keysAndValues.
+//
+// # Example
+//
+//	logger.DefaultLogger.ErrorKV(ctx, "Failed to ship order", "order", orderID, logger.Err(err))
+//	// Output: ERROR: Failed to ship order order=ord-789 error="carrier unavailable"
+func (l *ContextLogger) ErrorKV(ctx context.Context, msg string, keysAndValues ...any) {
+	l.logKV(ctx, LevelError, msg, keysAndValues)
+}
+
+// FatalKV logs a fatal error message with structured fields, runs the exit hooks
+// and then exits the program, like Fatal. See DebugKV for the format of keysAndValues.
+func (l *ContextLogger) FatalKV(ctx context.Context, msg string, keysAndValues ...any) {
+	l.fatal(ctx, msg, fieldsFromKV(keysAndValues))
+}
@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldConstructors(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	err := errors.New("boom")
+
+	assert.Equal(t, Field{Key: "s", Value: "v"}, String("s", "v"))
+	assert.Equal(t, Field{Key: "i", Value: 1}, Int("i", 1))
+	assert.Equal(t, Field{Key: "i64", Value: int64(2)}, Int64("i64", 2))
+	assert.Equal(t, Field{Key: "u64", Value: uint64(3)}, Uint64("u64", 3))
+	assert.Equal(t, Field{Key: "f", Value: 1.5}, Float64("f", 1.5))
+	assert.Equal(t, Field{Key: "b", Value: true}, Bool("b", true))
+	assert.Equal(t, Field{Key: "d", Value: time.Second}, Duration("d", time.Second))
+	assert.Equal(t, Field{Key: "t", Value: now}, Time("t", now))
+	assert.Equal(t, Field{Key: "error", Value: err}, Err(err))
+	assert.Equal(t, Field{Key: "a", Value: []int{1}}, Any("a", []int{1}))
+}
+
+func TestFieldsFromKV(t *testing.T) {
+	fields := fieldsFromKV([]any{"order", 42, Bool("paid", true), "name", "John", 7, "dangling"})
+	assert.Equal(t, []Field{
+		{Key: "order", Value: 42},
+		{Key: "paid", Value: true},
+		{Key: "name", Value: "John"},
+		{Key: badKey, Value: 7},
+		{Key: badKey, Value: "dangling"},
+	}, fields)
+
+	assert.Empty(t, fieldsFromKV(nil))
+}
+
+// pathError is an error type with a pointer receiver, to log typed nil errors.
+type pathError struct{ path string }
+
+func (e *pathError) Error() string { return "open " + e.path }
+
+// order is a fmt.Stringer with a pointer receiver.
+type order struct{ id string }
+
+func (o *order) String() string { return "order " + o.id }
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "<nil>", formatValue(nil))
+	assert.Equal(t, "text", formatValue("text"))
+	assert.Equal(t, "boom", formatValue(errors.New("boom")))
+	assert.Equal(t, "2025-01-02T03:04:05Z", formatValue(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)))
+	assert.Equal(t, "1.5s", formatValue(1500*time.Millisecond))
+	assert.Equal(t, "INFO", formatValue(LevelInfo))
+	assert.Equal(t, "42", formatValue(42))
+	assert.Equal(t, "[1 2]", formatValue([]int{1, 2}))
+	assert.Equal(t, "text", formatValue([]byte("text")))
+	assert.Equal(t, "order ord-789", formatValue(&order{id: "ord-789"}))
+}
+
+func TestFormatValueTypedNil(t *testing.T) {
+	var err *pathError
+	var o *order
+	assert.Equal(t, "<nil>", formatValue(error(err)))
+	assert.Equal(t, "<nil>", formatValue(o))
+
+	for _, encoder := range []Encoder{TextEncoder{}, JSONEncoder{}, LogfmtEncoder{}} {
+		var logOutput bytes.Buffer
+		ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), WithEncoder(encoder))
+		assert.NotPanics(t, func() {
+			ctxLogger.InfoKV(context.Background(), "Order failed", Err(err), "order", o)
+		}, "%T", encoder)
+		assert.Contains(t, logOutput.String(), "Order failed", "%T", encoder)
+		assert.Contains(t, logOutput.String(), "<nil>", "%T", encoder)
+	}
+}
+
+func TestQuoteIfNeeded(t *testing.T) {
+	assert.Equal(t, "plain", quoteIfNeeded("plain"))
+	assert.Equal(t, `""`, quoteIfNeeded(""))
+	assert.Equal(t, `"two words"`, quoteIfNeeded("two words"))
+	assert.Equal(t, `"a=b"`, quoteIfNeeded("a=b"))
+	assert.Equal(t, `"say \"hi\""`, quoteIfNeeded(`say "hi"`))
+	assert.Equal(t, `"line\nbreak"`, quoteIfNeeded("line\nbreak"))
+}
+
+func TestContextLoggerKV(t *testing.T) {
+	var buf bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&buf, "", 0), WithLevel(LevelDebug))
+	ctx := WithRequestID(context.Background(), "req-123")
+
+	tests := []struct {
+		name     string
+		log      func()
+		expected string
+	}{
+		{
+			name:     "debug",
+			log:      func() { ctxLogger.DebugKV(ctx, "Cache lookup", "key", "user-456", Bool("hit", true)) },
+			expected: "DEBUG: [request_id=req-123] Cache lookup key=user-456 hit=true\n",
+		},
+		{
+			name:     "info",
+			log:      func() { ctxLogger.InfoKV(ctx, "Order shipped", "order", "ord-789", "items", 3) },
+			expected: "INFO: [request_id=req-123] Order shipped order=ord-789 items=3\n",
+		},
+		{
+			name:     "warning",
+			log:      func() { ctxLogger.WarningKV(ctx, "Slow request", Duration("elapsed", 1500*time.Millisecond)) },
+			expected: "WARNING: [request_id=req-123] Slow request elapsed=1.5s\n",
+		},
+		{
+			name:     "error",
+			log:      func() { ctxLogger.ErrorKV(ctx, "Failed to ship order", Err(errors.New("carrier unavailable"))) },
+			expected: "ERROR: [request_id=req-123] Failed to ship order error=\"carrier unavailable\"\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tt.log()
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestContextLoggerKVDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&buf, "", 0))
+
+	ctxLogger.DebugKV(context.Background(), "Cache lookup", "key", "user-456")
+	assert.Empty(t, buf.String(), "Debug log should be discarded by default")
+}
+
+func TestContextLoggerWith(t *testing.T) {
+	var buf bytes.Buffer
+	parent := NewContextLogger(log.New(&buf, "", 0))
+	ctx := context.Background()
+
+	child := parent.With(String("service", "greeter"))
+	grandchild := child.With(Int("worker", 1))
+	sibling := child.With(Int("worker", 2))
+
+	child.Info(ctx, "Started %d workers", 2)
+	assert.Equal(t, "INFO: Started 2 workers service=greeter\n", buf.String())
+	buf.Reset()
+
+	grandchild.InfoKV(ctx, "Job done", "job", 7)
+	assert.Equal(t, "INFO: Job done service=greeter worker=1 job=7\n", buf.String())
+	buf.Reset()
+
+	sibling.Warning(ctx, "Job slow")
+	assert.Equal(t, "WARNING: Job slow service=greeter worker=2\n", buf.String(), "Siblings should not share fields")
+	buf.Reset()
+
+	parent.Info(ctx, "Stopped")
+	assert.Equal(t, "INFO: Stopped\n", buf.String(), "The parent should not be modified")
+	buf.Reset()
+
+	// Children share the parent's level
+	parent.SetLevel(LevelError)
+	grandchild.Info(ctx, "Job done")
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level is the severity of a log message. Levels are ordered: a logger configured
+// with a minimum level discards every message below it.
+//
+// The numeric values match those of log/slog, so a Level can be converted to an
+// slog.Level and back without a lookup table.
+type Level int
+
+// Log levels, in order of increasing severity.
+const (
+	// LevelDebug is for detailed diagnostic messages that are usually disabled in production.
+	LevelDebug Level = -4
+	// LevelInfo is for general information about application progress. It is the default minimum level.
+	LevelInfo Level = 0
+	// LevelWarning is for unusual situations that don't prevent the application from functioning.
+	LevelWarning Level = 4
+	// LevelError is for errors that prevent a specific operation from completing.
+	LevelError Level = 8
+	// LevelFatal is for critical errors that terminate the application.
+	LevelFatal Level = 12
+)
+
+// String returns the name of the level as it appears in log messages, such as "INFO".
+// Values between the predefined levels are rendered as "Level(n)".
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarning:
+		return "WARNING"
+	case LevelError:
+		return "ERROR"
+	case LevelFatal:
+		return "FATAL"
+	default:
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+}
+
+// ParseLevel converts a level name, such as "debug" or "WARNING", to a Level.
+// Names are case-insensitive and "warn" is accepted as an alias of "warning".
+//
+// # Example
+//
+//	level, err := logger.ParseLevel(os.Getenv("LOG_LEVEL"))
+//	if err != nil {
+//	    level = logger.LevelInfo
+//	}
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(s)) {
+	case "DEBUG":
+		return LevelDebug, nil
+	case "INFO":
+		return LevelInfo, nil
+	case "WARNING", "WARN":
+		return LevelWarning, nil
+	case "ERROR":
+		return LevelError, nil
+	case "FATAL":
+		return LevelFatal, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q", s)
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler, so that levels appear by name
+// in JSON and other text formats.
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseLevel.
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
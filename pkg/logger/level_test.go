@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevelString(t *testing.T) {
+	assert.Equal(t, "DEBUG", LevelDebug.String())
+	assert.Equal(t, "INFO", LevelInfo.String())
+	assert.Equal(t, "WARNING", LevelWarning.String())
+	assert.Equal(t, "ERROR", LevelError.String())
+	assert.Equal(t, "FATAL", LevelFatal.String())
+	assert.Equal(t, "Level(2)", Level(2).String())
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Level
+		wantErr  bool
+	}{
+		{input: "debug", expected: LevelDebug},
+		{input: "INFO", expected: LevelInfo},
+		{input: " Warning ", expected: LevelWarning},
+		{input: "warn", expected: LevelWarning},
+		{input: "error", expected: LevelError},
+		{input: "fatal", expected: LevelFatal},
+		{input: "verbose", expected: LevelInfo, wantErr: true},
+		{input: "", expected: LevelInfo, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			level, err := ParseLevel(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, level)
+		})
+	}
+}
+
+func TestLevelOrder(t *testing.T) {
+	assert.True(t, LevelDebug < LevelInfo)
+	assert.True(t, LevelInfo < LevelWarning)
+	assert.True(t, LevelWarning < LevelError)
+	assert.True(t, LevelError < LevelFatal)
+}
+
+func TestLevelText(t *testing.T) {
+	text, err := LevelWarning.MarshalText()
+	assert.NoError(t, err)
+	assert.Equal(t, "WARNING", string(text))
+
+	var level Level
+	assert.NoError(t, level.UnmarshalText([]byte("debug")))
+	assert.Equal(t, LevelDebug, level)
+	assert.Error(t, level.UnmarshalText([]byte("verbose")))
+	assert.Equal(t, LevelDebug, level, "A failed unmarshal should not change the level")
+}
@@ -3,9 +3,14 @@
 package logger
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
 )
 
 // contextKey is a type for context keys to avoid collisions.
@@ -17,6 +22,8 @@
 	RequestIDKey contextKey = "request_id"
 	// UserIDKey is the key for user ID in context.
 	UserIDKey contextKey = "user_id"
+	// TraceContextKey is the key for the W3C trace context in context.
+	TraceContextKey contextKey = "trace_context"
 )
 
 // ContextLogger is a logger that includes context information in log messages.
@@ -26,7 +33,8 @@
 // # Features
 //
 // - Automatically includes context information in log messages
-// - Provides methods for different log levels (Info, Warning, Error, Fatal)
+// - Provides methods for different log levels (Debug, Info, Warning, Error, Fatal)
+// - Discards messages below a configurable minimum level
 // - Uses the standard Go logger underneath for actual logging
 // - Thread-safe for concurrent use by multiple goroutines
 //
@@ -40,19 +48,80 @@
 type ContextLogger struct {
 	// logger is the underlying standard Go logger used for actual logging
 	logger *log.Logger
+	// level is the minimum level of the messages that are logged; it may be shared with other loggers
+	level *AtomicLevel
+	// fields are included in every message; they are added with With
+	fields []Field
+	// encoder renders the messages; the default is TextEncoder
+	encoder Encoder
+	// handler, if set, receives the messages instead of encoder and logger
+	handler slog.Handler
+	// redactor, if set, removes sensitive values from the messages before they are written
+	redactor *Redactor
+	// sampler, if set, limits how many similar messages are written
+	sampler *Sampler
+	// samplingLogger is the logger configured with WithSampler. The sampling
+	// summaries are written through it, so that they do not carry the fields of
+	// a logger created with With.
+	samplingLogger *ContextLogger
+	// addCaller records the file and line of the code that logged each message
+	addCaller bool
+	// callerSkip is the number of additional frames skipped to find the caller
+	callerSkip int
+	// stackLevel is the minimum level of the messages that carry a stack trace, unless noStack is set
+	stackLevel Level
+	noStack    bool
+	// exit is called by Fatal after the exit hooks have run; the default is os.Exit
+	exit func(code int)
+	// exitTimeout bounds how long Fatal waits for the exit hooks
+	exitTimeout time.Duration
+	// sinks, if set, receive the messages instead of encoder and logger
+	sinks []*Sink
+}
+
+// Option configures a ContextLogger created by NewContextLogger.
+type Option func(*ContextLogger)
+
+// WithLevel sets the minimum level of the messages that are logged. Messages
+// below this level are discarded without being formatted. The default is LevelInfo.
+func WithLevel(level Level) Option {
+	return func(l *ContextLogger) { l.level = NewAtomicLevel(level) }
+}
+
+// WithAtomicLevel makes the logger use the given AtomicLevel as its minimum level,
+// so that the level can be changed at runtime and shared with other loggers.
+func WithAtomicLevel(level *AtomicLevel) Option {
+	return func(l *ContextLogger) {
+		if level != nil {
+			l.level = level
+		}
+	}
+}
+
+// WithEncoder sets the encoder that renders the messages, such as JSONEncoder or
+// LogfmtEncoder. The default is TextEncoder.
+func WithEncoder(encoder Encoder) Option {
+	return func(l *ContextLogger) {
+		if encoder != nil {
+			l.encoder = encoder
+		}
+	}
 }
 
 // NewContextLogger creates a new ContextLogger with the provided logger.
 //
 // # Parameters
 //
-// - logger: A pointer to a standard Go logger that will be used for actual logging.
-//   If nil is provided, the function will use log.Default() instead.
+//   - logger: A pointer to a standard Go logger that will be used for actual logging.
+//     If nil is provided, the function will use log.Default() instead.
+//
+//   - opts: Optional settings such as WithLevel, WithAtomicLevel, WithEncoder, WithSlogHandler,
+//     WithRedactor, WithSampler, WithCaller, WithStackTrace, WithExitFunc or WithSinks.
 //
 // # Return Value
 //
-// - *ContextLogger: A new ContextLogger instance that wraps the provided logger
-//   and adds context-aware logging capabilities.
+//   - *ContextLogger: A new ContextLogger instance that wraps the provided logger
+//     and adds context-aware logging capabilities.
 //
 // # Example
 //
@@ -62,39 +131,145 @@ type ContextLogger struct {
 //
 //	// Use the default logger
 //	defaultContextLogger := logger.NewContextLogger(nil)
-func NewContextLogger(logger *log.Logger) *ContextLogger {
+//
+//	// Also log debug messages
+//	debugLogger := logger.NewContextLogger(stdLogger, logger.WithLevel(logger.LevelDebug))
+func NewContextLogger(logger *log.Logger, opts ...Option) *ContextLogger {
 	if logger == nil {
 		logger = log.Default()
 	}
-	return &ContextLogger{
-		logger: logger,
+	l := &ContextLogger{
+		logger:      logger,
+		level:       NewAtomicLevel(LevelInfo),
+		encoder:     TextEncoder{},
+		stackLevel:  LevelError,
+		exit:        os.Exit,
+		exitTimeout: DefaultExitTimeout,
+	}
+	for _, opt := range opts {
+		opt(l)
 	}
+	return l
+}
+
+// Enabled reports whether messages of the given level are logged. Use it to skip
+// expensive work that only serves to build a log message.
+//
+// # Example
+//
+//	if logger.DefaultLogger.Enabled(ctx, logger.LevelDebug) {
+//	    logger.DefaultLogger.Debug(ctx, "State: %s", dumpState())
+//	}
+func (l *ContextLogger) Enabled(ctx context.Context, level Level) bool {
+	if !l.level.Enabled(level) {
+		return false
+	}
+	if l.handler != nil {
+		return l.handler.Enabled(contextOrBackground(ctx), slog.Level(level))
+	}
+	return l.sinksEnabled(level)
+}
+
+// Level returns the logger's current minimum level.
+func (l *ContextLogger) Level() Level {
+	return l.level.Level()
+}
+
+// SetLevel changes the logger's minimum level, and that of every logger sharing
+// its AtomicLevel.
+func (l *ContextLogger) SetLevel(level Level) {
+	l.level.SetLevel(level)
 }
 
-// extractContextInfo extracts relevant information from the context and formats it.
-func extractContextInfo(ctx context.Context) string {
-	if ctx == nil {
-		return ""
+// AtomicLevel returns the handle of the logger's minimum level, for example to
+// expose it over HTTP or to share it with other loggers through WithAtomicLevel.
+func (l *ContextLogger) AtomicLevel() *AtomicLevel {
+	return l.level
+}
+
+// logf formats and writes a message of the given level, unless the level is
+// disabled or the message is sampled out.
+func (l *ContextLogger) logf(ctx context.Context, level Level, format string, v []interface{}) {
+	if !l.Enabled(ctx, level) || !l.sampled(level, format) {
+		return
 	}
+	l.write(ctx, level, fmt.Sprintf(format, v...), nil)
+}
 
-	var contextInfo string
+// bufferPool recycles the buffers that entries are encoded into.
+var bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
 
-	// Extract request ID if present
-	if requestID, ok := ctx.Value(RequestIDKey).(string); ok && requestID != "" {
-		contextInfo += fmt.Sprintf("request_id=%s ", requestID)
+// write logs a message of the given level, together with the context values,
+// the logger's fields and the given fields. It must be called by a helper of the
+// public logging method, such as logf, so that the caller is found three frames up.
+func (l *ContextLogger) write(ctx context.Context, level Level, msg string, fields []Field) {
+	entry := &Entry{Time: time.Now(), Level: level, Message: msg, pc: l.callerPC(level, 3)}
+	l.writeEntry(ctx, entry, fields)
+}
+
+// writeEntry completes the entry with the context values, the logger's fields
+// and the given fields, adds the caller and stack trace, redacts it, and writes
+// it to the logger's slog.Handler or sinks, if any, or encodes it and writes it
+// to the underlying logger.
+func (l *ContextLogger) writeEntry(ctx context.Context, entry *Entry, fields []Field) {
+	entry.Context = contextFields(ctx)
+	entry.Fields = l.fields
+	if len(fields) > 0 {
+		entry.Fields = append(l.fields[:len(l.fields):len(l.fields)], fields...)
+	}
+	depth := l.completeCaller(entry)
+	if l.redactor != nil {
+		l.redactor.Redact(entry)
 	}
 
-	// Extract user ID if present
-	if userID, ok := ctx.Value(UserIDKey).(string); ok && userID != "" {
-		contextInfo += fmt.Sprintf("user_id=%s ", userID)
+	if l.handler != nil {
+		_ = l.handler.Handle(contextOrBackground(ctx), entryRecord(entry))
+		return
+	}
+	if l.sinks != nil {
+		l.writeSinks(entry, max(depth, 1))
+		return
 	}
 
-	// Add more context extractions as needed
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer bufferPool.Put(buf)
+	buf.Reset()
+	encodeEntry(buf, l.encoder, entry)
+	// Make the log.Lshortfile and log.Llongfile flags report the caller.
+	l.logger.Output(max(depth, 1), buf.String())
+}
 
-	if contextInfo != "" {
-		return "[" + contextInfo[:len(contextInfo)-1] + "] " // Remove trailing space and add brackets
+// encodeEntry encodes the entry into buf, falling back to the level and message
+// if the encoder fails.
+func encodeEntry(buf *bytes.Buffer, encoder Encoder, entry *Entry) {
+	if err := encoder.Encode(buf, entry); err != nil {
+		buf.Reset()
+		fmt.Fprintf(buf, "%s: %s (log encoding failed: %v)", entry.Level, entry.Message, err)
 	}
-	return ""
+}
+
+// Debug logs a debug message with context information.
+//
+// Use Debug for detailed diagnostic information that is only needed when
+// investigating a problem. Debug messages are discarded unless the logger's
+// minimum level is LevelDebug.
+//
+// # Parameters
+//
+//   - ctx: A context.Context that may contain values like request ID or user ID
+//     that will be automatically included in the log message.
+//
+// - format: A format string, similar to fmt.Printf, that specifies the message format.
+//
+// - v: Optional arguments to be formatted according to the format string.
+//
+// # Example
+//
+//	ctx := context.Background()
+//	logger.DefaultLogger.Debug(ctx, "Cache lookup for key %q", key)
+//	// Output: DEBUG: Cache lookup for key "user-456"
+func (l *ContextLogger) Debug(ctx context.Context, format string, v ...interface{}) {
+	l.logf(ctx, LevelDebug, format, v)
 }
 
 // Info logs an informational message with context information.
@@ -105,8 +280,8 @@ func extractContextInfo(ctx context.Context) string {
 //
 // # Parameters
 //
-// - ctx: A context.Context that may contain values like request ID or user ID
-//   that will be automatically included in the log message.
+//   - ctx: A context.Context that may contain values like request ID or user ID
+//     that will be automatically included in the log message.
 //
 // - format: A format string, similar to fmt.Printf, that specifies the message format.
 //
@@ -123,8 +298,7 @@ func extractContextInfo(ctx context.Context) string {
 //	logger.DefaultLogger.Info(ctx, "Processing item %d", itemID)
 //	// Output: INFO: [request_id=req-123] Processing item 123
 func (l *ContextLogger) Info(ctx context.Context, format string, v ...interface{}) {
-	contextInfo := extractContextInfo(ctx)
-	l.logger.Printf("INFO: %s"+format, append([]interface{}{contextInfo}, v...)...)
+	l.logf(ctx, LevelInfo, format, v)
 }
 
 // Warning logs a warning message with context information.
@@ -135,8 +309,8 @@ func (l *ContextLogger) Info(ctx context.Context, format string, v ...interface{
 //
 // # Parameters
 //
-// - ctx: A context.Context that may contain values like request ID or user ID
-//   that will be automatically included in the log message.
+//   - ctx: A context.Context that may contain values like request ID or user ID
+//     that will be automatically included in the log message.
 //
 // - format: A format string, similar to fmt.Printf, that specifies the message format.
 //
@@ -148,8 +322,7 @@ func (l *ContextLogger) Info(ctx context.Context, format string, v ...interface{
 //	logger.DefaultLogger.Warning(ctx, "Unusual condition detected: %v", condition)
 //	// Output: WARNING: Unusual condition detected: value out of range
 func (l *ContextLogger) Warning(ctx context.Context, format string, v ...interface{}) {
-	contextInfo := extractContextInfo(ctx)
-	l.logger.Printf("WARNING: %s"+format, append([]interface{}{contextInfo}, v...)...)
+	l.logf(ctx, LevelWarning, format, v)
 }
 
 // Error logs an error message with context information.
@@ -160,8 +333,8 @@ func (l *ContextLogger) Warning(ctx context.Context, format string, v ...interfa
 //
 // # Parameters
 //
-// - ctx: A context.Context that may contain values like request ID or user ID
-//   that will be automatically included in the log message.
+//   - ctx: A context.Context that may contain values like request ID or user ID
+//     that will be automatically included in the log message.
 //
 // - format: A format string, similar to fmt.Printf, that specifies the message format.
 //
@@ -173,19 +346,21 @@ func (l *ContextLogger) Warning(ctx context.Context, format string, v ...interfa
 //	logger.DefaultLogger.Error(ctx, "Failed to process item %d: %v", itemID, err)
 //	// Output: ERROR: Failed to process item 123: file not found
 func (l *ContextLogger) Error(ctx context.Context, format string, v ...interface{}) {
-	contextInfo := extractContextInfo(ctx)
-	l.logger.Printf("ERROR: %s"+format, append([]interface{}{contextInfo}, v...)...)
+	l.logf(ctx, LevelError, format, v)
 }
 
 // Fatal logs a fatal error message with context information and then exits the program.
 //
 // Use Fatal for critical errors that prevent the application from continuing.
-// After logging the message, this method will call os.Exit(1) to terminate the program.
+// After logging the message, this method runs the exit hooks registered with
+// RegisterExitHook, waiting at most DefaultExitTimeout (see WithExitTimeout), so
+// that buffered log messages are flushed and files are closed. It then calls
+// os.Exit(1), or the function set with WithExitFunc, to terminate the program.
 //
 // # Parameters
 //
-// - ctx: A context.Context that may contain values like request ID or user ID
-//   that will be automatically included in the log message.
+//   - ctx: A context.Context that may contain values like request ID or user ID
+//     that will be automatically included in the log message.
 //
 // - format: A format string, similar to fmt.Printf, that specifies the message format.
 //
@@ -201,10 +376,17 @@ func (l *ContextLogger) Error(ctx context.Context, format string, v ...interface
 // # Important Note
 //
 // This method will terminate the program. Use it only for errors that make it
-// impossible for the application to continue running.
+// impossible for the application to continue running. Fatal messages are logged
+// regardless of the logger's minimum level. Deferred calls are not run; register
+// the cleanup that must happen anyway as an exit hook.
 func (l *ContextLogger) Fatal(ctx context.Context, format string, v ...interface{}) {
-	contextInfo := extractContextInfo(ctx)
-	l.logger.Fatalf("FATAL: %s"+format, append([]interface{}{contextInfo}, v...)...)
+	l.fatal(ctx, fmt.Sprintf(format, v...), nil)
+}
+
+// fatal writes a fatal message, regardless of the minimum level, and exits the program.
+func (l *ContextLogger) fatal(ctx context.Context, msg string, fields []Field) {
+	l.write(ctx, LevelFatal, msg, fields)
+	l.exitProgram(1)
 }
 
 // DefaultLogger is a singleton instance of ContextLogger that can be used throughout the application.
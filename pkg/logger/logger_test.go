@@ -36,7 +36,12 @@ func TestContextLogger(t *testing.T) {
 	assert.True(t, strings.Contains(buf.String(), "ERROR: test error message"), "Error log should contain the message")
 	buf.Reset()
 
-	// We can't easily test Fatal as it calls os.Exit
+	// Test Fatal logging without exiting
+	exitCode := 0
+	fatalLogger := NewContextLogger(logger, WithExitFunc(func(code int) { exitCode = code }))
+	fatalLogger.Fatal(ctx, "test fatal message")
+	assert.True(t, strings.Contains(buf.String(), "FATAL: test fatal message"), "Fatal log should contain the message")
+	assert.Equal(t, 1, exitCode, "Fatal should exit with status 1")
 }
 
 func TestContextLoggerWithContextValues(t *testing.T) {
@@ -78,37 +83,30 @@ func TestContextLoggerWithContextValues(t *testing.T) {
 	buf.Reset()
 }
 
-func TestExtractContextInfo(t *testing.T) {
-	// Test with nil context
-	assert.Equal(t, "", extractContextInfo(nil), "Nil context should return empty string")
-
-	// Test with empty context
+func TestContextFields(t *testing.T) {
+	// Test with nil and empty contexts
+	assert.Empty(t, contextFields(nil), "Nil context should have no fields")
 	ctx := context.Background()
-	assert.Equal(t, "", extractContextInfo(ctx), "Empty context should return empty string")
+	assert.Empty(t, contextFields(ctx), "Empty context should have no fields")
 
 	// Test with request ID only
 	ctx = WithRequestID(ctx, "req-123")
-	assert.Equal(t, "[request_id=req-123] ", extractContextInfo(ctx), "Context with request ID should return formatted string")
+	assert.Equal(t, []Field{String("request_id", "req-123")}, contextFields(ctx))
 
 	// Test with user ID only
-	ctx = context.Background()
-	ctx = WithUserID(ctx, "user-456")
-	assert.Equal(t, "[user_id=user-456] ", extractContextInfo(ctx), "Context with user ID should return formatted string")
+	ctx = WithUserID(context.Background(), "user-456")
+	assert.Equal(t, []Field{String("user_id", "user-456")}, contextFields(ctx))
 
-	// Test with both request ID and user ID
-	ctx = context.Background()
-	ctx = WithRequestID(ctx, "req-123")
-	ctx = WithUserID(ctx, "user-456")
-	contextInfo := extractContextInfo(ctx)
-	assert.True(t, contextInfo == "[request_id=req-123 user_id=user-456] " ||
-		contextInfo == "[user_id=user-456 request_id=req-123] ",
-		"Context with both IDs should return formatted string with both values: %s", contextInfo)
+	// Test with both request ID and user ID, which TextEncoder renders in brackets
+	ctx = WithUserID(WithRequestID(context.Background(), "req-123"), "user-456")
+	assert.Equal(t, []Field{String("request_id", "req-123"), String("user_id", "user-456")}, contextFields(ctx))
+	var buf bytes.Buffer
+	assert.NoError(t, TextEncoder{}.Encode(&buf, &Entry{Level: LevelInfo, Message: "Processing", Context: contextFields(ctx)}))
+	assert.Equal(t, "INFO: [request_id=req-123 user_id=user-456] Processing", buf.String())
 
 	// Test with empty values
-	ctx = context.Background()
-	ctx = WithRequestID(ctx, "")
-	ctx = WithUserID(ctx, "")
-	assert.Equal(t, "", extractContextInfo(ctx), "Context with empty values should return empty string")
+	ctx = WithUserID(WithRequestID(context.Background(), ""), "")
+	assert.Empty(t, contextFields(ctx), "Context with empty values should have no fields")
 }
 
 func TestWithRequestID(t *testing.T) {
@@ -142,3 +140,52 @@ func TestDefaultLogger(t *testing.T) {
 	// Test that DefaultLogger is not nil
 	assert.NotNil(t, DefaultLogger, "DefaultLogger should not be nil")
 }
+
+func TestContextLoggerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := context.Background()
+
+	// The default minimum level is Info, so Debug is discarded
+	ctxLogger := NewContextLogger(log.New(&buf, "", 0))
+	assert.False(t, ctxLogger.Enabled(ctx, LevelDebug))
+	assert.True(t, ctxLogger.Enabled(ctx, LevelInfo))
+	ctxLogger.Debug(ctx, "test debug message")
+	assert.Empty(t, buf.String(), "Debug log should be discarded by default")
+	ctxLogger.Info(ctx, "test info message")
+	assert.Equal(t, "INFO: test info message\n", buf.String())
+	buf.Reset()
+
+	// Debug is logged once enabled
+	ctxLogger = NewContextLogger(log.New(&buf, "", 0), WithLevel(LevelDebug))
+	ctxLogger.Debug(WithRequestID(ctx, "req-123"), "test debug message %d", 1)
+	assert.Equal(t, "DEBUG: [request_id=req-123] test debug message 1\n", buf.String())
+	buf.Reset()
+
+	// Messages below the minimum level are discarded
+	ctxLogger = NewContextLogger(log.New(&buf, "", 0), WithLevel(LevelError))
+	ctxLogger.Info(ctx, "test info message")
+	ctxLogger.Warning(ctx, "test warning message")
+	assert.Empty(t, buf.String(), "Info and Warning logs should be discarded")
+	ctxLogger.Error(ctx, "test error message")
+	assert.Equal(t, "ERROR: test error message\n", buf.String())
+}
+
+// countingStringer counts how often it is formatted.
+type countingStringer struct{ calls *int }
+
+func (s countingStringer) String() string {
+	*s.calls++
+	return "value"
+}
+
+func TestContextLoggerDisabledSkipsFormatting(t *testing.T) {
+	var buf bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&buf, "", 0), WithLevel(LevelWarning))
+
+	calls := 0
+	ctxLogger.Info(context.Background(), "expensive %s", countingStringer{&calls})
+	assert.Equal(t, 0, calls, "Arguments of disabled messages should not be formatted")
+
+	ctxLogger.Warning(context.Background(), "expensive %s", countingStringer{&calls})
+	assert.Equal(t, 1, calls)
+}
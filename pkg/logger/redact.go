@@ -0,0 +1,292 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// DefaultRedactionMask replaces masked values unless a Redactor is configured
+// otherwise with WithRedactionMask. Sensitive values always render as it.
+const DefaultRedactionMask = "[REDACTED]"
+
+// hashPrefix marks values replaced by their keyed hash, e.g. "hmac:3f2a...".
+const hashPrefix = "hmac:"
+
+// Sensitive is a string that must not appear in logs, such as a person's name or
+// an e-mail address. It always renders as DefaultRedactionMask, whether it is
+// formatted with fmt, logged as a field value, encoded as JSON or passed to
+// log/slog, so it can be logged without a Redactor:
+//
+//	logger.DefaultLogger.Info(ctx, "Generating greeting for '%s'", logger.Sensitive(name))
+//	// Output: INFO: Generating greeting for '[REDACTED]'
+//
+// A Redactor with a rule for the field name can still hash the underlying value,
+// so that log entries about the same person can be correlated:
+//
+//	logger.DefaultLogger.InfoKV(ctx, "User signed in", "email", logger.Sensitive(email))
+//	// Output with RedactFields(RedactHash, "email"): INFO: User signed in email=hmac:5d41402abc4b2a76b9719d911017c592
+type Sensitive string
+
+// String returns DefaultRedactionMask.
+func (Sensitive) String() string {
+	return DefaultRedactionMask
+}
+
+// GoString returns DefaultRedactionMask, so that the %#v verb does not reveal
+// the value either.
+func (Sensitive) GoString() string {
+	return DefaultRedactionMask
+}
+
+// MarshalJSON renders the value as the JSON string "[REDACTED]".
+func (Sensitive) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + DefaultRedactionMask + `"`), nil
+}
+
+// LogValue implements slog.LogValuer, so that slog handlers render the value
+// as DefaultRedactionMask.
+func (Sensitive) LogValue() slog.Value {
+	return slog.StringValue(DefaultRedactionMask)
+}
+
+// RedactAction determines how a Redactor replaces a sensitive value.
+type RedactAction int
+
+const (
+	// RedactMask replaces the value with the redaction mask, "[REDACTED]" by default.
+	RedactMask RedactAction = iota
+	// RedactHash replaces the value with a keyed HMAC-SHA256 hash, such as
+	// "hmac:5d41402abc4b2a76b9719d911017c592". Equal values have equal hashes, so
+	// entries can still be joined on them, but the value cannot be recovered
+	// without the key. It requires WithHashKey.
+	RedactHash
+	// RedactDrop removes the value: fields are left out of the entry and matches
+	// of a pattern are removed from the text.
+	RedactDrop
+)
+
+// String returns the name of the action, e.g. "RedactHash".
+func (a RedactAction) String() string {
+	switch a {
+	case RedactMask:
+		return "RedactMask"
+	case RedactHash:
+		return "RedactHash"
+	case RedactDrop:
+		return "RedactDrop"
+	default:
+		return fmt.Sprintf("RedactAction(%d)", int(a))
+	}
+}
+
+// patternRule is a regular expression whose matches are redacted.
+type patternRule struct {
+	re     *regexp.Regexp
+	action RedactAction
+}
+
+// Redactor removes secrets and personal data from log entries before they are
+// encoded or passed to a slog.Handler. Its rules select sensitive values:
+//
+// - RedactPattern selects the matches of a regular expression in the message, fields and context values.
+// - RedactFields selects the values of the fields with the given keys.
+// - RedactContextKeys selects the context values with the given names, such as "user_id".
+//
+// Each rule masks, hashes or drops what it selects. A value selected by its name
+// is replaced as a whole and not matched against the patterns.
+//
+// # Usage
+//
+// Attach a Redactor to a logger with WithRedactor:
+//
+//	redactor, err := logger.NewRedactor(
+//	    logger.RedactPattern(regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.]+`), logger.RedactMask),
+//	    logger.RedactFields(logger.RedactDrop, "password", "token"),
+//	    logger.RedactContextKeys(logger.RedactHash, "user_id"),
+//	    logger.WithHashKey(hashKey),
+//	)
+//	if err != nil {
+//	    log.Fatalf("Error: %v", err)
+//	}
+//	ctxLogger := logger.NewContextLogger(nil, logger.WithRedactor(redactor))
+//
+// A Redactor is immutable and safe for concurrent use by multiple goroutines.
+type Redactor struct {
+	patterns    []patternRule
+	fields      map[string]RedactAction
+	contextKeys map[string]RedactAction
+	hashKey     []byte
+	mask        string
+}
+
+// RedactOption configures a Redactor created by NewRedactor.
+type RedactOption func(*Redactor)
+
+// RedactPattern redacts the matches of re in messages and in string values.
+func RedactPattern(re *regexp.Regexp, action RedactAction) RedactOption {
+	return func(r *Redactor) { r.patterns = append(r.patterns, patternRule{re: re, action: action}) }
+}
+
+// RedactFields redacts the values of the fields with the given keys. Keys are
+// compared case-insensitively.
+func RedactFields(action RedactAction, keys ...string) RedactOption {
+	return func(r *Redactor) { addKeys(r.fields, action, keys) }
+}
+
+// RedactContextKeys redacts the context values logged under the given names,
+// such as "request_id" or "user_id" (see Extractor). Names are compared
+// case-insensitively.
+func RedactContextKeys(action RedactAction, names ...string) RedactOption {
+	return func(r *Redactor) { addKeys(r.contextKeys, action, names) }
+}
+
+// addKeys records the action for each key; later rules override earlier ones.
+func addKeys(rules map[string]RedactAction, action RedactAction, keys []string) {
+	for _, key := range keys {
+		rules[strings.ToLower(key)] = action
+	}
+}
+
+// WithHashKey sets the secret key of the HMAC used by RedactHash. Use the same
+// key in every process whose logs are joined, and keep it out of the logs.
+func WithHashKey(key []byte) RedactOption {
+	return func(r *Redactor) { r.hashKey = append([]byte(nil), key...) }
+}
+
+// WithRedactionMask sets the text that replaces values redacted by RedactMask.
+// The default is DefaultRedactionMask.
+func WithRedactionMask(mask string) RedactOption {
+	return func(r *Redactor) { r.mask = mask }
+}
+
+// NewRedactor creates a Redactor with the given rules.
+//
+// # Parameters
+//
+// - opts: Rules such as RedactPattern, RedactFields and RedactContextKeys, and settings such as WithHashKey.
+//
+// # Return Values
+//
+// - *Redactor: The redactor, to be attached to a logger with WithRedactor.
+//
+// - error: An error if a rule is invalid or RedactHash is used without WithHashKey.
+func NewRedactor(opts ...RedactOption) (*Redactor, error) {
+	r := &Redactor{
+		fields:      make(map[string]RedactAction),
+		contextKeys: make(map[string]RedactAction),
+		mask:        DefaultRedactionMask,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	actions := make([]RedactAction, 0, len(r.patterns)+len(r.fields)+len(r.contextKeys))
+	for _, p := range r.patterns {
+		if p.re == nil {
+			return nil, errors.New("logger: redaction pattern must not be nil")
+		}
+		actions = append(actions, p.action)
+	}
+	for _, rules := range []map[string]RedactAction{r.fields, r.contextKeys} {
+		for _, action := range rules {
+			actions = append(actions, action)
+		}
+	}
+	for _, action := range actions {
+		if action < RedactMask || action > RedactDrop {
+			return nil, fmt.Errorf("logger: unknown redaction action %v", action)
+		}
+		if action == RedactHash && len(r.hashKey) == 0 {
+			return nil, errors.New("logger: RedactHash requires a key set with WithHashKey")
+		}
+	}
+	return r, nil
+}
+
+// WithRedactor makes the logger redact every entry with r before it is encoded
+// or passed to its slog.Handler. Loggers without a Redactor, such as
+// DefaultLogger, log context values such as user_id verbatim.
+func WithRedactor(r *Redactor) Option {
+	return func(l *ContextLogger) { l.redactor = r }
+}
+
+// Redact redacts the message, the context values and the fields of the entry.
+// It replaces the entry's slices rather than modifying them, because they may be
+// shared with the logger.
+func (r *Redactor) Redact(e *Entry) {
+	e.Message = r.RedactString(e.Message)
+	e.Context = r.redactFields(e.Context, r.contextKeys)
+	e.Fields = r.redactFields(e.Fields, r.fields)
+}
+
+// RedactString returns s with the matches of the patterns redacted.
+//
+// # Example
+//
+//	redactor, _ := logger.NewRedactor(logger.RedactPattern(regexp.MustCompile(`\d{4}-\d{4}-\d{4}-\d{4}`), logger.RedactMask))
+//	redactor.RedactString("Charged card 4111-1111-1111-1111")
+//	// Result: "Charged card [REDACTED]"
+func (r *Redactor) RedactString(s string) string {
+	for _, p := range r.patterns {
+		s = p.re.ReplaceAllStringFunc(s, func(match string) string {
+			return r.replace(p.action, match)
+		})
+	}
+	return s
+}
+
+// redactFields returns a copy of fields in which the values selected by the
+// rules for their keys, or else by the patterns, are redacted. The patterns are
+// matched against the text the encoders render for any value, such as the
+// message of an error, but a value they leave unchanged keeps its type.
+func (r *Redactor) redactFields(fields []Field, rules map[string]RedactAction) []Field {
+	if len(fields) == 0 {
+		return fields
+	}
+
+	redacted := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		if action, ok := rules[strings.ToLower(f.Key)]; ok {
+			if action == RedactDrop {
+				continue
+			}
+			f.Value = r.replace(action, rawValue(f.Value))
+		} else if _, ok := f.Value.(Sensitive); !ok && len(r.patterns) > 0 {
+			s := formatValue(f.Value)
+			if redactedValue := r.RedactString(s); redactedValue != s {
+				f.Value = redactedValue
+			}
+		}
+		redacted = append(redacted, f)
+	}
+	return redacted
+}
+
+// replace returns the replacement of the sensitive text s for the action.
+func (r *Redactor) replace(action RedactAction, s string) string {
+	switch action {
+	case RedactHash:
+		mac := hmac.New(sha256.New, r.hashKey)
+		mac.Write([]byte(s))
+		return hashPrefix + hex.EncodeToString(mac.Sum(nil)[:16])
+	case RedactDrop:
+		return ""
+	default:
+		return r.mask
+	}
+}
+
+// rawValue renders a field value as text, revealing the value of Sensitive
+// values so that they can be hashed.
+func rawValue(v any) string {
+	if s, ok := v.(Sensitive); ok {
+		return string(s)
+	}
+	return formatValue(v)
+}
@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSensitive(t *testing.T) {
+	secret := Sensitive("John")
+
+	assert.Equal(t, "[REDACTED]", fmt.Sprint(secret))
+	assert.Equal(t, "Hello, [REDACTED]", fmt.Sprintf("Hello, %s", secret))
+	assert.Equal(t, `"[REDACTED]"`, fmt.Sprintf("%q", secret))
+	assert.Equal(t, "[REDACTED]", fmt.Sprintf("%#v", secret))
+	assert.NotContains(t, fmt.Sprintf("%x", secret), fmt.Sprintf("%x", "John"))
+
+	b, err := json.Marshal(map[string]any{"name": secret})
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name":"[REDACTED]"}`, string(b))
+
+	var slogOutput bytes.Buffer
+	slog.New(slog.NewTextHandler(&slogOutput, nil)).Info("hello", "name", secret)
+	assert.Contains(t, slogOutput.String(), "name=[REDACTED]")
+
+	for _, encoder := range []Encoder{TextEncoder{}, JSONEncoder{}, LogfmtEncoder{}} {
+		var logOutput bytes.Buffer
+		ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), WithEncoder(encoder))
+		ctxLogger.Info(context.Background(), "Greeting %s", secret)
+		ctxLogger.InfoKV(context.Background(), "Greeting", "name", secret)
+		assert.NotContains(t, logOutput.String(), "John", "%T should not reveal the value", encoder)
+		assert.Equal(t, 2, strings.Count(logOutput.String(), "[REDACTED]"), "%T", encoder)
+	}
+}
+
+func TestRedactor(t *testing.T) {
+	email := regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.]+`)
+	card := regexp.MustCompile(`\b\d{4}-\d{4}-\d{4}-\d{4}\b`)
+	redactor, err := NewRedactor(
+		RedactPattern(email, RedactMask),
+		RedactPattern(card, RedactDrop),
+		RedactFields(RedactDrop, "password"),
+		RedactFields(RedactHash, "Name"),
+		RedactContextKeys(RedactHash, "user_id"),
+		WithHashKey([]byte("secret")),
+	)
+	assert.NoError(t, err)
+
+	var logOutput bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), WithRedactor(redactor)).With(String("contact", "ops@example.com"))
+	ctx := WithUserID(WithRequestID(context.Background(), "req-123"), "user-456")
+
+	ctxLogger.InfoKV(ctx, "Mail to jane@example.com paid with 4111-1111-1111-1111",
+		"password", "hunter2", "name", Sensitive("Jane"), "NAME", "Jane", "items", 3)
+	line := logOutput.String()
+
+	hashedUser := redactor.replace(RedactHash, "user-456")
+	hashedName := redactor.replace(RedactHash, "Jane")
+	assert.Regexp(t, `^hmac:[0-9a-f]{32}$`, hashedUser)
+	assert.Equal(t, "INFO: [request_id=req-123 user_id="+hashedUser+"] Mail to [REDACTED] paid with "+
+		" contact=[REDACTED] name="+hashedName+" NAME="+hashedName+" items=3\n", line)
+	assert.NotContains(t, line, "hunter2")
+
+	logOutput.Reset()
+	ctxLogger.Info(ctx, "No fields")
+	assert.Equal(t, "INFO: [request_id=req-123 user_id="+hashedUser+"] No fields contact=[REDACTED]\n", logOutput.String(),
+		"Hashes should be stable, so that entries can be joined")
+}
+
+// address is a fmt.Stringer that renders an e-mail address.
+type address struct{ user, domain string }
+
+func (a address) String() string { return a.user + "@" + a.domain }
+
+func TestRedactorPatternsOnFormattedValues(t *testing.T) {
+	email := regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.]+`)
+	redactor, err := NewRedactor(RedactPattern(email, RedactMask))
+	assert.NoError(t, err)
+
+	var textOutput, jsonOutput bytes.Buffer
+	textLogger := NewContextLogger(log.New(&textOutput, "", 0), WithRedactor(redactor))
+	jsonLogger := NewContextLogger(log.New(&jsonOutput, "", 0), WithRedactor(redactor), WithEncoder(JSONEncoder{}))
+	for _, l := range []*ContextLogger{textLogger, jsonLogger} {
+		l.With(Any("to", address{"jane", "example.com"})).WarningKV(context.Background(), "Delivery failed",
+			Err(fmt.Errorf("mailbox jane@example.com is full")), "raw", []byte("from ops@example.com"), "items", 3)
+	}
+
+	assert.Equal(t, "WARNING: Delivery failed to=[REDACTED] error=\"mailbox [REDACTED] is full\" "+
+		"raw=\"from [REDACTED]\" items=3\n", textOutput.String())
+	assert.NotContains(t, jsonOutput.String(), "example.com")
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal(jsonOutput.Bytes(), &decoded))
+	assert.Equal(t, float64(3), decoded["items"], "Values the patterns don't match should keep their type")
+}
+
+func TestRedactorDoesNotModifyLoggerFields(t *testing.T) {
+	redactor, err := NewRedactor(RedactFields(RedactMask, "token"))
+	assert.NoError(t, err)
+
+	var logOutput bytes.Buffer
+	base := NewContextLogger(log.New(&logOutput, "", 0), WithRedactor(redactor))
+	fields := []Field{String("token", "abc"), String("service", "api")}
+	child := base.With(fields...)
+
+	child.Info(context.Background(), "Calling")
+	assert.Equal(t, "INFO: Calling token=[REDACTED] service=api\n", logOutput.String())
+	assert.Equal(t, "abc", fields[0].Value)
+	assert.Equal(t, "abc", child.fields[0].Value)
+}
+
+func TestRedactorWithSlogHandler(t *testing.T) {
+	redactor, err := NewRedactor(RedactContextKeys(RedactDrop, "user_id"), WithRedactionMask("***"),
+		RedactPattern(regexp.MustCompile(`secret-\w+`), RedactMask))
+	assert.NoError(t, err)
+
+	var jsonOutput bytes.Buffer
+	handler := slog.NewJSONHandler(&jsonOutput, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	ctxLogger := NewContextLogger(nil, WithSlogHandler(handler), WithRedactor(redactor))
+
+	ctxLogger.InfoKV(WithUserID(context.Background(), "user-456"), "Using secret-abc", "key", "secret-def")
+	assert.JSONEq(t, `{"level":"INFO","msg":"Using ***","key":"***"}`, jsonOutput.String())
+}
+
+func TestNewRedactorErrors(t *testing.T) {
+	_, err := NewRedactor(RedactFields(RedactHash, "email"))
+	assert.EqualError(t, err, "logger: RedactHash requires a key set with WithHashKey")
+
+	_, err = NewRedactor(RedactPattern(nil, RedactMask))
+	assert.EqualError(t, err, "logger: redaction pattern must not be nil")
+
+	_, err = NewRedactor(RedactContextKeys(RedactAction(7), "user_id"))
+	assert.EqualError(t, err, "logger: unknown redaction action RedactAction(7)")
+
+	redactor, err := NewRedactor()
+	assert.NoError(t, err)
+	assert.Equal(t, "unchanged", redactor.RedactString("unchanged"))
+}
+
+func TestRedactActionString(t *testing.T) {
+	assert.Equal(t, "RedactMask", RedactMask.String())
+	assert.Equal(t, "RedactHash", RedactHash.String())
+	assert.Equal(t, "RedactDrop", RedactDrop.String())
+	assert.Equal(t, "RedactAction(7)", RedactAction(7).String())
+}
@@ -0,0 +1,454 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultMaxBackups is the number of rotated files a RotatingFile keeps unless
+// configured otherwise with WithMaxBackups.
+const DefaultMaxBackups = 7
+
+// rotationRetryDelay is how long a RotatingFile waits after a failed rotation
+// before it tries again, writing to the current file in the meantime.
+const rotationRetryDelay = time.Minute
+
+// RotatingFile is an io.Writer that appends to a log file and rotates it when it
+// reaches a maximum size or when a time interval has passed. Rotated files are
+// renamed with a numeric suffix, the most recent being path.1, and optionally
+// compressed with gzip (path.1.gz) in the background. Only the most recent
+// backups are kept.
+//
+// # Usage
+//
+// Use a RotatingFile as the writer of a log.Logger, possibly behind an
+// AsyncWriter so that rotation and compression never hold up the caller:
+//
+//	file, err := logger.NewRotatingFile("/var/log/app.log",
+//	    logger.WithMaxSize(10<<20),
+//	    logger.WithRotationInterval(24*time.Hour),
+//	    logger.WithMaxBackups(7),
+//	    logger.WithCompression(),
+//	)
+//	if err != nil {
+//	    log.Fatalf("Error: %v", err)
+//	}
+//	defer file.Close()
+//	ctxLogger := logger.NewContextLogger(log.New(file, "", log.LstdFlags))
+//
+// When the file is rotated by an external tool such as logrotate instead, call
+// Reopen after the file has been moved, typically on SIGHUP.
+//
+// A RotatingFile is safe for concurrent use by multiple goroutines.
+type RotatingFile struct {
+	path       string
+	maxSize    int64
+	interval   time.Duration
+	maxBackups int
+	compress   bool
+	clock      func() time.Time
+	// openFile opens the log file; it is os.OpenFile except in tests.
+	openFile func(name string, flag int, perm fs.FileMode) (*os.File, error)
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	// rotateAt is when the file is rotated next; it is zero without an interval.
+	rotateAt time.Time
+	// retryAt holds off rotation after a failure; it is zero after a success.
+	retryAt time.Time
+	// compressing is closed when the background compression of the latest
+	// backup has finished, after compressErr has been set. It is nil when no
+	// compression has been started since the last one was collected.
+	compressing chan struct{}
+	compressErr error
+}
+
+// RotateOption configures a RotatingFile created by NewRotatingFile.
+type RotateOption func(*RotatingFile)
+
+// WithMaxSize rotates the file before a write would make it larger than the given
+// number of bytes. Zero, the default, disables rotation by size. A single write
+// larger than the maximum is written to a fresh file rather than split, and the
+// file may grow beyond the maximum while the previous backup is compressed.
+func WithMaxSize(bytes int64) RotateOption {
+	return func(f *RotatingFile) { f.maxSize = max(bytes, 0) }
+}
+
+// WithRotationInterval rotates the file at every multiple of the interval, such
+// as every day at midnight UTC for 24*time.Hour. Zero, the default, disables
+// rotation by time.
+func WithRotationInterval(d time.Duration) RotateOption {
+	return func(f *RotatingFile) { f.interval = max(d, 0) }
+}
+
+// WithMaxBackups sets how many rotated files are kept; older ones are deleted.
+// With zero backups, the file is truncated when it rotates.
+func WithMaxBackups(n int) RotateOption {
+	return func(f *RotatingFile) { f.maxBackups = max(n, 0) }
+}
+
+// WithCompression compresses rotated files with gzip.
+func WithCompression() RotateOption {
+	return func(f *RotatingFile) { f.compress = true }
+}
+
+// WithRotationClock sets the function that returns the current time, which
+// drives rotation by interval. It is mainly useful in tests.
+func WithRotationClock(now func() time.Time) RotateOption {
+	return func(f *RotatingFile) {
+		if now != nil {
+			f.clock = now
+		}
+	}
+}
+
+// NewRotatingFile opens the log file at path for appending, creating it if
+// necessary, and returns a RotatingFile that writes to it. Without options, the
+// file never rotates on its own and DefaultMaxBackups backups are kept when
+// Rotate is called.
+//
+// # Parameters
+//
+// - path: The path of the log file. Backups are created next to it.
+//
+// - opts: Optional settings such as WithMaxSize, WithRotationInterval, WithMaxBackups and WithCompression.
+//
+// # Return Values
+//
+// - *RotatingFile: The open file. Close it when it is no longer used.
+//
+// - error: An error if the file cannot be opened.
+func NewRotatingFile(path string, opts ...RotateOption) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, maxBackups: DefaultMaxBackups, clock: time.Now, openFile: os.OpenFile}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p to the file, rotating it first if p does not fit within the
+// maximum size or the rotation interval has passed. If the rotation fails, p is
+// still written to the current file and the rotation error is returned; the
+// rotation is retried by the first write a minute later. While the previous
+// backup is still being compressed, the rotation is postponed to a later write
+// rather than waited for, so that Write never blocks on the compression.
+//
+// Write returns ErrClosed after the file was closed.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, ErrClosed
+	}
+
+	var rotateErr error
+	if f.shouldRotate(int64(len(p))) {
+		rotateErr = f.rotate()
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err != nil {
+		return n, err
+	}
+	return n, rotateErr
+}
+
+// shouldRotate reports whether the file must be rotated before writing n bytes.
+func (f *RotatingFile) shouldRotate(n int64) bool {
+	if !f.retryAt.IsZero() && f.clock().Before(f.retryAt) {
+		return false
+	}
+	if f.maxSize > 0 && f.size > 0 && f.size+n > f.maxSize {
+		return true
+	}
+	return !f.rotateAt.IsZero() && !f.clock().Before(f.rotateAt)
+}
+
+// Rotate rotates the file immediately, regardless of its size and age. If the
+// previous backup is still being compressed, Rotate first waits for it, without
+// holding up concurrent writes. The new backup is compressed in the background;
+// Close waits for it.
+func (f *RotatingFile) Rotate() error {
+	for {
+		f.mu.Lock()
+		if f.file == nil {
+			f.mu.Unlock()
+			return ErrClosed
+		}
+		compressing := f.compressing
+		if compressing == nil || isDone(compressing) {
+			err := f.rotate()
+			f.mu.Unlock()
+			return err
+		}
+		f.mu.Unlock()
+		<-compressing
+	}
+}
+
+// Reopen closes and reopens the file at its path. Call it after an external tool
+// such as logrotate has moved the file, so that subsequent messages go to a new
+// file at the original path rather than to the moved one. If the file cannot be
+// opened, the current file stays open and the error is returned, so that a later
+// Reopen can try again.
+//
+// # Example
+//
+//	hup := make(chan os.Signal, 1)
+//	signal.Notify(hup, syscall.SIGHUP)
+//	go func() {
+//	    for range hup {
+//	        if err := file.Reopen(); err != nil {
+//	            logger.DefaultLogger.Error(ctx, "Failed to reopen log file: %v", err)
+//	        }
+//	    }
+//	}()
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return ErrClosed
+	}
+	old := f.file
+	if err := f.open(); err != nil {
+		return err
+	}
+	if err := old.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+	return nil
+}
+
+// Close closes the file and waits for the compression of the latest backup.
+// Calling Close more than once is safe.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.file == nil {
+		f.mu.Unlock()
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	compressing := f.compressing
+	f.compressing = nil
+	f.mu.Unlock()
+
+	if compressing == nil {
+		return err
+	}
+	<-compressing
+	return errors.Join(compressionError(f.compressErr), err)
+}
+
+// open opens the file at f.path for appending and, if that succeeds, makes it
+// the current file, resetting the size and the rotation time. The previous file,
+// if any, is left open. The caller must hold f.mu.
+func (f *RotatingFile) open() error {
+	file, err := f.openFile(f.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("open log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.resetRotation()
+	return nil
+}
+
+// resetRotation schedules the next rotation by interval and clears the retry
+// delay of a failed rotation. The caller must hold f.mu.
+func (f *RotatingFile) resetRotation() {
+	f.rotateAt = time.Time{}
+	if f.interval > 0 {
+		f.rotateAt = f.clock().Truncate(f.interval).Add(f.interval)
+	}
+	f.retryAt = time.Time{}
+}
+
+// rotate moves the current file to the first backup, shifting and pruning the
+// existing backups, and opens a new file. The backup is compressed in the
+// background; an error of the previous compression is returned along with the
+// result of the rotation. The backups cannot be shifted while the latest one is
+// being compressed, so the rotation is skipped until the compression has
+// finished. If the current file cannot be moved or the new one cannot be
+// opened, the current file stays in use at its path and rotation is held off
+// for rotationRetryDelay. The caller must hold f.mu.
+func (f *RotatingFile) rotate() error {
+	if f.maxBackups == 0 {
+		if err := f.file.Truncate(0); err != nil {
+			return f.rotationFailed(err)
+		}
+		f.size = 0
+		f.resetRotation()
+		return nil
+	}
+
+	if f.compressing != nil && !isDone(f.compressing) {
+		return nil
+	}
+	var compressErr error
+	if f.compressing != nil {
+		compressErr = compressionError(f.compressErr)
+		f.compressing = nil
+	}
+
+	// If the file is gone, a rotation that could not open the new file also
+	// failed to move it back, or it was moved by another tool. The backups
+	// must not be shifted again; a new file just has to be opened.
+	_, err := os.Stat(f.path)
+	moved := errors.Is(err, fs.ErrNotExist)
+	backup := f.backupName(1, "")
+	if !moved {
+		if err := f.shiftBackups(); err != nil {
+			return errors.Join(f.rotationFailed(err), compressErr)
+		}
+		if err := os.Rename(f.path, backup); err != nil {
+			return errors.Join(f.rotationFailed(err), compressErr)
+		}
+	}
+
+	old := f.file
+	if err := f.open(); err != nil {
+		if !moved {
+			// Move the file back, so that it stays at its path and the next
+			// attempt starts over from the same state.
+			err = errors.Join(err, os.Rename(backup, f.path))
+		}
+		return errors.Join(f.rotationFailed(err), compressErr)
+	}
+	old.Close()
+
+	if f.compress && !moved {
+		done := make(chan struct{})
+		f.compressing = done
+		go func() {
+			f.compressErr = compressFile(backup)
+			close(done)
+		}()
+	}
+	return compressErr
+}
+
+// rotationFailed holds off rotation for rotationRetryDelay and returns the
+// rotation error. The caller must hold f.mu.
+func (f *RotatingFile) rotationFailed(err error) error {
+	f.retryAt = f.clock().Add(rotationRetryDelay)
+	return fmt.Errorf("rotate log file: %w", err)
+}
+
+// isDone reports whether the channel is closed, without blocking.
+func isDone(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// compressionError wraps the error of a background compression, if any.
+func compressionError(err error) error {
+	if err != nil {
+		return fmt.Errorf("compress rotated log file: %w", err)
+	}
+	return nil
+}
+
+// backupName returns the name of the i-th most recent backup with the given
+// extension, e.g. "app.log.1.gz".
+func (f *RotatingFile) backupName(i int, ext string) string {
+	return f.path + "." + strconv.Itoa(i) + ext
+}
+
+// shiftBackups renames the backups so that the first backup name becomes free.
+// Only the backups before the first free name are renamed, so the oldest backup
+// is deleted only when every name is taken, and a failed rotation that leaves
+// the first name free does not cost a backup on retry. Compressed and
+// uncompressed backups are both handled, so that compression can be turned on
+// and off between runs.
+func (f *RotatingFile) shiftBackups() error {
+	exts := []string{"", ".gz"}
+	last := f.maxBackups
+	for i := 1; i < f.maxBackups; i++ {
+		if !f.backupExists(i, exts) {
+			last = i
+			break
+		}
+	}
+	for _, ext := range exts {
+		if err := os.Remove(f.backupName(last, ext)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	for i := last - 1; i >= 1; i-- {
+		for _, ext := range exts {
+			err := os.Rename(f.backupName(i, ext), f.backupName(i+1, ext))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// backupExists reports whether the i-th backup exists with any of the extensions.
+func (f *RotatingFile) backupExists(i int, exts []string) bool {
+	for _, ext := range exts {
+		if _, err := os.Lstat(f.backupName(i, ext)); !errors.Is(err, fs.ErrNotExist) {
+			return true
+		}
+	}
+	return false
+}
+
+// compressFile replaces the file at path with a gzip-compressed copy at path.gz.
+func compressFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			dst.Close()
+			os.Remove(path + ".gz")
+		}
+	}()
+
+	zw := gzip.NewWriter(dst)
+	if _, err := io.Copy(zw, src); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -0,0 +1,351 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// readFile returns the content of the file at path, decompressing .gz files.
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if filepath.Ext(path) == ".gz" {
+		zr, err := gzip.NewReader(f)
+		if !assert.NoError(t, err) {
+			return ""
+		}
+		r = zr
+	}
+	content, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	return string(content)
+}
+
+// listDir returns the names of the files in dir, sorted.
+func listDir(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotatingFileBySize(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f, err := NewRotatingFile(path, WithMaxSize(10), WithMaxBackups(2))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	for _, msg := range []string{"one\n", "two\n", "three\n", "four\n", "five\n", "a very long line\n"} {
+		n, err := f.Write([]byte(msg))
+		assert.NoError(t, err)
+		assert.Equal(t, len(msg), n)
+	}
+
+	assert.Equal(t, []string{"app.log", "app.log.1", "app.log.2"}, listDir(t, dir))
+	assert.Equal(t, "a very long line\n", readFile(t, path), "Oversized writes go to a fresh file")
+	assert.Equal(t, "four\nfive\n", readFile(t, path+".1"))
+	assert.Equal(t, "three\n", readFile(t, path+".2"), "Older backups are deleted")
+}
+
+func TestRotatingFileAppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	assert.NoError(t, os.WriteFile(path, []byte("12345678\n"), 0o644))
+
+	f, err := NewRotatingFile(path, WithMaxSize(10))
+	assert.NoError(t, err)
+	_, err = f.Write([]byte("next\n"))
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+
+	assert.Equal(t, "12345678\n", readFile(t, path+".1"), "The size of the existing file counts")
+	assert.Equal(t, "next\n", readFile(t, path))
+}
+
+func TestRotatingFileByInterval(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	var mu sync.Mutex
+	now := time.Date(2025, 1, 2, 23, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	advance := func(d time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(d)
+	}
+
+	f, err := NewRotatingFile(path, WithRotationInterval(24*time.Hour), WithRotationClock(clock))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	_, _ = f.Write([]byte("day 1\n"))
+	advance(59 * time.Minute)
+	_, _ = f.Write([]byte("day 1 late\n"))
+	advance(time.Minute) // midnight
+	_, _ = f.Write([]byte("day 2\n"))
+	advance(12 * time.Hour)
+	_, _ = f.Write([]byte("day 2 noon\n"))
+
+	assert.Equal(t, []string{"app.log", "app.log.1"}, listDir(t, dir))
+	assert.Equal(t, "day 1\nday 1 late\n", readFile(t, path+".1"))
+	assert.Equal(t, "day 2\nday 2 noon\n", readFile(t, path))
+}
+
+func TestRotatingFileCompression(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	// An uncompressed backup from a previous run without compression.
+	assert.NoError(t, os.WriteFile(path+".1", []byte("old\n"), 0o644))
+
+	f, err := NewRotatingFile(path, WithCompression(), WithMaxBackups(3))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	_, _ = f.Write([]byte("first\n"))
+	assert.NoError(t, f.Rotate())
+	_, _ = f.Write([]byte("second\n"))
+	assert.NoError(t, f.Rotate())
+	// Close waits for the background compression.
+	assert.NoError(t, f.Close())
+
+	assert.Equal(t, []string{"app.log", "app.log.1.gz", "app.log.2.gz", "app.log.3"}, listDir(t, dir))
+	assert.Equal(t, "second\n", readFile(t, path+".1.gz"))
+	assert.Equal(t, "first\n", readFile(t, path+".2.gz"))
+	assert.Equal(t, "old\n", readFile(t, path+".3"))
+	assert.Equal(t, "", readFile(t, path))
+}
+
+func TestRotatingFileWithoutBackups(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f, err := NewRotatingFile(path, WithMaxSize(8), WithMaxBackups(0))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	_, _ = f.Write([]byte("first\n"))
+	_, _ = f.Write([]byte("second\n"))
+	assert.Equal(t, []string{"app.log"}, listDir(t, dir))
+	assert.Equal(t, "second\n", readFile(t, path))
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f, err := NewRotatingFile(path)
+	assert.NoError(t, err)
+
+	_, _ = f.Write([]byte("before\n"))
+	// Simulate logrotate moving the file away.
+	assert.NoError(t, os.Rename(path, path+".moved"))
+	_, _ = f.Write([]byte("still old\n"))
+	assert.NoError(t, f.Reopen())
+	_, _ = f.Write([]byte("after\n"))
+	assert.NoError(t, f.Close())
+
+	assert.Equal(t, "before\nstill old\n", readFile(t, path+".moved"))
+	assert.Equal(t, "after\n", readFile(t, path))
+}
+
+func TestRotatingFileReopenFailure(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	assert.NoError(t, os.Mkdir(dir, 0o755))
+	path := filepath.Join(dir, "app.log")
+	f, err := NewRotatingFile(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	// The directory disappears, so the file cannot be reopened.
+	assert.NoError(t, os.Rename(dir, dir+".moved"))
+	assert.ErrorContains(t, f.Reopen(), "open log file")
+	_, err = f.Write([]byte("kept\n"))
+	assert.NoError(t, err, "The old file should stay in use after a failed Reopen")
+
+	assert.NoError(t, os.Mkdir(dir, 0o755))
+	assert.NoError(t, f.Reopen(), "A later Reopen should succeed")
+	_, _ = f.Write([]byte("after\n"))
+
+	assert.Equal(t, "kept\n", readFile(t, filepath.Join(dir+".moved", "app.log")))
+	assert.Equal(t, "after\n", readFile(t, path))
+}
+
+func TestRotatingFileOpenFailureAfterMove(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	assert.NoError(t, os.WriteFile(path+".1", []byte("old\n"), 0o644))
+	f, err := NewRotatingFile(path, WithMaxBackups(2))
+	assert.NoError(t, err)
+	defer f.Close()
+	_, _ = f.Write([]byte("current\n"))
+
+	// The file is moved to the first backup, but the new file cannot be opened.
+	errOpen := errors.New("too many open files")
+	f.openFile = func(string, int, fs.FileMode) (*os.File, error) { return nil, errOpen }
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, f.Rotate(), errOpen)
+		_, err = f.Write([]byte("retry\n"))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{"app.log", "app.log.2"}, listDir(t, dir),
+		"The file should be moved back and retries should not shift the backups again")
+	assert.Equal(t, "current\nretry\nretry\n", readFile(t, path))
+	assert.Equal(t, "old\n", readFile(t, path+".2"))
+
+	f.openFile = os.OpenFile
+	assert.NoError(t, f.Rotate())
+	_, _ = f.Write([]byte("rotated\n"))
+	assert.Equal(t, []string{"app.log", "app.log.1", "app.log.2"}, listDir(t, dir))
+	assert.Equal(t, "current\nretry\nretry\n", readFile(t, path+".1"))
+	assert.Equal(t, "rotated\n", readFile(t, path))
+
+	// If the file is missing, rotation opens a new one without shifting the
+	// backups, which still hold the file that is being written.
+	assert.NoError(t, os.Rename(path, path+".moved"))
+	assert.NoError(t, f.Rotate())
+	_, _ = f.Write([]byte("reopened\n"))
+	assert.Equal(t, []string{"app.log", "app.log.1", "app.log.2", "app.log.moved"}, listDir(t, dir))
+	assert.Equal(t, "rotated\n", readFile(t, path+".moved"))
+	assert.Equal(t, "reopened\n", readFile(t, path))
+	assert.Equal(t, "old\n", readFile(t, path+".2"))
+}
+
+func TestRotatingFileCompressionDoesNotBlockWrites(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f, err := NewRotatingFile(path, WithMaxSize(4), WithCompression())
+	assert.NoError(t, err)
+	defer f.Close()
+
+	// Pretend that the previous backup is still being compressed.
+	compressing := make(chan struct{})
+	f.mu.Lock()
+	f.compressing = compressing
+	f.mu.Unlock()
+
+	rotated := make(chan error, 1)
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		for _, msg := range []string{"one\n", "two\n"} {
+			_, err := f.Write([]byte(msg))
+			assert.NoError(t, err)
+		}
+		go func() { rotated <- f.Rotate() }()
+		_, err := f.Write([]byte("three\n"))
+		assert.NoError(t, err)
+	}()
+	select {
+	case <-written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Writes should not wait for a running compression")
+	}
+	assert.Equal(t, []string{"app.log"}, listDir(t, dir), "Rotation should be postponed until the compression has finished")
+
+	close(compressing)
+	assert.NoError(t, <-rotated, "Rotate should rotate once the compression has finished")
+	assert.NoError(t, f.Close())
+	assert.Equal(t, []string{"app.log", "app.log.1.gz"}, listDir(t, dir))
+	assert.Equal(t, "one\ntwo\nthree\n", readFile(t, path+".1.gz"))
+}
+
+func TestRotatingFileClosed(t *testing.T) {
+	f, err := NewRotatingFile(filepath.Join(t.TempDir(), "app.log"))
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	assert.NoError(t, f.Close(), "Close is idempotent")
+
+	_, err = f.Write([]byte("late\n"))
+	assert.ErrorIs(t, err, ErrClosed)
+	assert.ErrorIs(t, f.Rotate(), ErrClosed)
+	assert.ErrorIs(t, f.Reopen(), ErrClosed)
+}
+
+func TestRotatingFileErrors(t *testing.T) {
+	_, err := NewRotatingFile(filepath.Join(t.TempDir(), "missing", "app.log"))
+	assert.ErrorContains(t, err, "open log file")
+
+	// A directory in place of the first backup makes rotation fail, but the
+	// message is still written.
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	assert.NoError(t, os.MkdirAll(filepath.Join(path+".1", "blocker"), 0o755))
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	f, err := NewRotatingFile(path, WithMaxSize(4), WithMaxBackups(1), WithRotationClock(func() time.Time { return now }))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	_, err = f.Write([]byte("one\n"))
+	assert.NoError(t, err)
+	n, err := f.Write([]byte("two\n"))
+	assert.ErrorContains(t, err, "rotate log file")
+	assert.Equal(t, 4, n)
+	assert.Equal(t, "one\ntwo\n", readFile(t, path))
+
+	// The rotation is not retried by every write.
+	_, err = f.Write([]byte("three\n"))
+	assert.NoError(t, err, "Rotation should be held off after a failure")
+	now = now.Add(rotationRetryDelay)
+	_, err = f.Write([]byte("four\n"))
+	assert.ErrorContains(t, err, "rotate log file", "Rotation should be retried after the delay")
+
+	assert.NoError(t, os.RemoveAll(path+".1"))
+	now = now.Add(rotationRetryDelay)
+	_, err = f.Write([]byte("five\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "one\ntwo\nthree\nfour\n", readFile(t, path+".1"))
+	assert.Equal(t, "five\n", readFile(t, path))
+}
+
+func TestRotatingFileConcurrentUse(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f, err := NewRotatingFile(path, WithMaxSize(100), WithMaxBackups(100))
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_, err := f.Write([]byte("0123456789\n"))
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.NoError(t, f.Close())
+
+	total := 0
+	for _, name := range listDir(t, dir) {
+		content := readFile(t, filepath.Join(dir, name))
+		assert.LessOrEqual(t, len(content), 100)
+		total += len(content)
+	}
+	assert.Equal(t, 200*11, total)
+}
@@ -0,0 +1,260 @@
+package logger
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultSamplingInterval is the interval of a Sampler unless configured
+// otherwise.
+const DefaultSamplingInterval = time.Second
+
+// samplingKey identifies the messages that a Sampler counts together: those of
+// the same level logged with the same format string (or message of the *KV
+// methods), which usually means from the same call site.
+type samplingKey struct {
+	level  Level
+	format string
+}
+
+// samplingCount counts the messages of one key in the current interval.
+type samplingCount struct {
+	seen       uint64
+	suppressed uint64
+}
+
+// samplingSummary reports the messages of one key suppressed in an interval.
+type samplingSummary struct {
+	key        samplingKey
+	suppressed uint64
+	elapsed    time.Duration
+}
+
+// Sampler limits how many similar messages a logger writes. Within each
+// interval, it lets through the first messages of every level and format string
+// and then only every Mth one, so that a message logged in a tight loop cannot
+// flood the logs while rare messages are always written.
+//
+// # Usage
+//
+// Attach a Sampler to a logger with WithSampler:
+//
+//	// Per second, log the first 10 occurrences of each message, then every 100th.
+//	sampler := logger.NewSampler(10, 100, time.Second)
+//	ctxLogger := logger.NewContextLogger(nil, logger.WithSampler(sampler))
+//
+// When an interval in which messages were suppressed ends, the logger writes a
+// summary for each suppressed message, even if nothing is logged afterwards:
+//
+//	INFO: Sampling suppressed 1890 messages in 1s format="Generating greeting for '%s'"
+//
+// Call FlushSampling on the logger before the program exits to write the
+// summaries of the current interval.
+//
+// Fatal messages are never sampled. Messages written through SlogHandler are not
+// sampled either.
+//
+// A Sampler is safe for concurrent use by multiple goroutines. Loggers created
+// with With share the sampler of their parent; the summaries are written
+// through the logger that was configured with WithSampler, without the fields
+// added by With.
+type Sampler struct {
+	first      uint64
+	thereafter uint64
+	interval   time.Duration
+	clock      func() time.Time
+	suppressed atomic.Uint64
+
+	mu          sync.Mutex
+	windowStart time.Time
+	counts      map[samplingKey]*samplingCount
+	// timer writes the summaries when the interval ends; it is armed by the
+	// first suppressed message of an interval. timerGen identifies the current
+	// timer, so that a stopped timer that has already fired does nothing.
+	timer    *time.Timer
+	timerGen uint64
+}
+
+// SamplerOption configures a Sampler created by NewSampler.
+type SamplerOption func(*Sampler)
+
+// WithSamplingClock sets the function that returns the current time, which
+// determines when an interval ends. It is mainly useful in tests. The summaries
+// are written at the end of an interval as measured by the clock, either when a
+// message is logged or when a timer running on real time finds it has ended.
+func WithSamplingClock(now func() time.Time) SamplerOption {
+	return func(s *Sampler) {
+		if now != nil {
+			s.clock = now
+		}
+	}
+}
+
+// NewSampler creates a Sampler.
+//
+// # Parameters
+//
+// - first: The number of messages of each level and format string written per interval before sampling.
+//
+// - thereafter: After the first messages, only every thereafter-th one is written; zero suppresses all.
+//
+// - interval: The period after which the counts are reset; zero or less selects DefaultSamplingInterval.
+//
+// - opts: Optional settings such as WithSamplingClock.
+//
+// # Return Value
+//
+// - *Sampler: The sampler, to be attached to a logger with WithSampler.
+func NewSampler(first, thereafter int, interval time.Duration, opts ...SamplerOption) *Sampler {
+	if interval <= 0 {
+		interval = DefaultSamplingInterval
+	}
+	s := &Sampler{
+		first:      uint64(max(first, 0)),
+		thereafter: uint64(max(thereafter, 0)),
+		interval:   interval,
+		clock:      time.Now,
+		counts:     make(map[samplingKey]*samplingCount),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.windowStart = s.clock()
+	return s
+}
+
+// WithSampler makes the logger sample its messages with s. See Sampler.
+func WithSampler(s *Sampler) Option {
+	return func(l *ContextLogger) {
+		l.sampler = s
+		l.samplingLogger = l
+	}
+}
+
+// Suppressed returns the total number of messages the sampler has suppressed.
+func (s *Sampler) Suppressed() uint64 {
+	return s.suppressed.Load()
+}
+
+// sample counts a message and reports whether it should be written. If the
+// interval has ended, the counts are reset and the summaries of the ended
+// interval are returned, to be written before the message. The first message
+// suppressed in an interval arms a timer that writes the summaries with l, the
+// logger configured with WithSampler, when the interval ends.
+func (s *Sampler) sample(level Level, format string, l *ContextLogger) (bool, []samplingSummary) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summaries []samplingSummary
+	now := s.clock()
+	if now.Sub(s.windowStart) >= s.interval {
+		summaries = s.reset(now)
+	}
+
+	key := samplingKey{level: level, format: format}
+	count := s.counts[key]
+	if count == nil {
+		count = &samplingCount{}
+		s.counts[key] = count
+	}
+	count.seen++
+	if count.seen <= s.first || (s.thereafter > 0 && (count.seen-s.first)%s.thereafter == 0) {
+		return true, summaries
+	}
+	count.suppressed++
+	s.suppressed.Add(1)
+	if s.timer == nil {
+		gen := s.timerGen
+		s.timer = time.AfterFunc(s.windowStart.Add(s.interval).Sub(now), func() {
+			l.writeSamplingSummaries(s.expire(gen))
+		})
+	}
+	return false, summaries
+}
+
+// expire is called by the timer with the given generation. If it is still the
+// current timer and the interval has ended, it resets the counts and returns the
+// summaries of the ended interval.
+func (s *Sampler) expire(gen uint64) []samplingSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if gen != s.timerGen {
+		return nil
+	}
+	s.timer = nil
+	s.timerGen++
+	if now := s.clock(); now.Sub(s.windowStart) >= s.interval {
+		return s.reset(now)
+	}
+	// The clock has not reached the end of the interval; the next suppressed
+	// message arms a new timer.
+	return nil
+}
+
+// flush resets the counts and returns the summaries of the current interval.
+func (s *Sampler) flush() []samplingSummary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reset(s.clock())
+}
+
+// reset starts a new interval at now, stopping the timer of the interval that
+// ended, and returns the summaries of the keys with suppressed messages in it.
+// The caller must hold s.mu.
+func (s *Sampler) reset(now time.Time) []samplingSummary {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+		s.timerGen++
+	}
+	elapsed := min(now.Sub(s.windowStart), s.interval)
+	var summaries []samplingSummary
+	for key, count := range s.counts {
+		if count.suppressed > 0 {
+			summaries = append(summaries, samplingSummary{key: key, suppressed: count.suppressed, elapsed: elapsed})
+		}
+	}
+	slices.SortFunc(summaries, func(a, b samplingSummary) int {
+		return cmp.Or(cmp.Compare(a.key.level, b.key.level), cmp.Compare(a.key.format, b.key.format))
+	})
+	clear(s.counts)
+	s.windowStart = now
+	return summaries
+}
+
+// sampled reports whether a message of the given level and format string should
+// be written, after writing the summaries of an ended sampling interval.
+func (l *ContextLogger) sampled(level Level, format string) bool {
+	if l.sampler == nil {
+		return true
+	}
+	ok, summaries := l.sampler.sample(level, format, l.samplingLogger)
+	l.samplingLogger.writeSamplingSummaries(summaries)
+	return ok
+}
+
+// writeSamplingSummaries writes a message at the level of each summary. The
+// messages are not tied to the context of any of the suppressed messages.
+func (l *ContextLogger) writeSamplingSummaries(summaries []samplingSummary) {
+	for _, s := range summaries {
+		msg := fmt.Sprintf("Sampling suppressed %d messages in %v", s.suppressed, s.elapsed)
+		entry := &Entry{Time: time.Now(), Level: s.key.level, Message: msg}
+		l.writeEntry(context.Background(), entry, []Field{String("format", s.key.format)})
+	}
+}
+
+// FlushSampling writes the summaries of the messages suppressed by the logger's
+// Sampler in the current interval and starts a new interval. Call it before the
+// program exits, so that the last summaries are not lost. It does nothing if the
+// logger has no Sampler.
+func (l *ContextLogger) FlushSampling() {
+	if l.sampler == nil {
+		return
+	}
+	l.samplingLogger.writeSamplingSummaries(l.sampler.flush())
+}
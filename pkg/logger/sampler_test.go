@@ -0,0 +1,185 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a settable clock for samplers.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// lockedBuffer is a bytes.Buffer that can be read while it is written.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestSampler(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	sampler := NewSampler(2, 3, time.Second, WithSamplingClock(clock.Now))
+
+	var logOutput bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), WithSampler(sampler))
+	ctx := context.Background()
+
+	for i := 1; i <= 10; i++ {
+		ctxLogger.Info(ctx, "Greeting %d", i)
+		ctxLogger.WarningKV(ctx, "Slow greeting", "n", i)
+	}
+	ctxLogger.Info(ctx, "Done")
+
+	lines := strings.Split(strings.TrimSpace(logOutput.String()), "\n")
+	assert.Equal(t, []string{
+		"INFO: Greeting 1", "WARNING: Slow greeting n=1",
+		"INFO: Greeting 2", "WARNING: Slow greeting n=2",
+		"INFO: Greeting 5", "WARNING: Slow greeting n=5",
+		"INFO: Greeting 8", "WARNING: Slow greeting n=8",
+		"INFO: Done",
+	}, lines, "The first 2 and then every 3rd message of each format should be written")
+	assert.Equal(t, uint64(12), sampler.Suppressed())
+
+	logOutput.Reset()
+	clock.Advance(1500 * time.Millisecond)
+	ctxLogger.Info(ctx, "Greeting %d", 11)
+	assert.Equal(t, "INFO: Sampling suppressed 6 messages in 1s format=\"Greeting %d\"\n"+
+		"WARNING: Sampling suppressed 6 messages in 1s format=\"Slow greeting\"\n"+
+		"INFO: Greeting 11\n", logOutput.String(), "A new interval should start with the summaries of the last one")
+
+	logOutput.Reset()
+	ctxLogger.Info(ctx, "Greeting %d", 12)
+	ctxLogger.Info(ctx, "Greeting %d", 13)
+	assert.Equal(t, "INFO: Greeting 12\n", logOutput.String())
+}
+
+func TestSamplerSummaryAfterSilence(t *testing.T) {
+	var logOutput lockedBuffer
+	sampler := NewSampler(1, 0, 20*time.Millisecond)
+	ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), WithSampler(sampler))
+
+	for i := 0; i < 5; i++ {
+		ctxLogger.Warning(context.Background(), "Retrying")
+	}
+	assert.Equal(t, "WARNING: Retrying\n", logOutput.String())
+
+	// Nothing is logged after the burst, but the summary is written anyway.
+	assert.Eventually(t, func() bool {
+		return strings.Contains(logOutput.String(), "WARNING: Sampling suppressed 4 messages in ")
+	}, 5*time.Second, 5*time.Millisecond, "The summary should be written when the interval ends")
+
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, 1, strings.Count(logOutput.String(), "Sampling suppressed"), "The summary should be written once")
+}
+
+func TestSamplerSummaryWithoutChildFields(t *testing.T) {
+	var logOutput lockedBuffer
+	sampler := NewSampler(1, 0, 20*time.Millisecond)
+	ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), WithSampler(sampler), WithEncoder(LogfmtEncoder{}))
+	child := ctxLogger.With(String("worker", "1"))
+
+	for i := 0; i < 3; i++ {
+		child.Warning(context.Background(), "Retrying")
+	}
+	assert.Eventually(t, func() bool {
+		return strings.Contains(logOutput.String(), `msg="Sampling suppressed 2 messages in `)
+	}, 5*time.Second, 5*time.Millisecond, "The summary should be written when the interval ends")
+
+	lines := strings.Split(strings.TrimSpace(logOutput.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "worker=1")
+	assert.NotContains(t, lines[1], "worker=1", "The summary should not carry the fields of the child that was sampled")
+}
+
+func TestSamplerThereafterZero(t *testing.T) {
+	var logOutput bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), WithSampler(NewSampler(1, 0, time.Hour)))
+
+	for i := 0; i < 100; i++ {
+		ctxLogger.Info(context.Background(), "Tick")
+	}
+	assert.Equal(t, "INFO: Tick\n", logOutput.String())
+}
+
+func TestFlushSampling(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	sampler := NewSampler(0, 0, time.Minute, WithSamplingClock(clock.Now))
+
+	var logOutput bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), WithSampler(sampler))
+	child := ctxLogger.With(String("worker", "1"))
+
+	ctxLogger.Error(WithRequestID(context.Background(), "req-123"), "Failed")
+	child.Error(context.Background(), "Failed")
+	assert.Empty(t, logOutput.String())
+
+	clock.Advance(20 * time.Second)
+	ctxLogger.FlushSampling()
+	assert.Equal(t, "ERROR: Sampling suppressed 2 messages in 20s format=Failed\n", logOutput.String(),
+		"Loggers created with With should share the sampler")
+
+	logOutput.Reset()
+	ctxLogger.FlushSampling()
+	NewContextLogger(log.New(&logOutput, "", 0)).FlushSampling()
+	assert.Empty(t, logOutput.String())
+}
+
+func TestSamplerConcurrency(t *testing.T) {
+	var logOutput bytes.Buffer
+	sampler := NewSampler(10, 10, time.Hour)
+	ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), WithSampler(sampler))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				ctxLogger.Info(context.Background(), "Greeting")
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 109, strings.Count(logOutput.String(), "INFO: Greeting\n"))
+	assert.Equal(t, uint64(891), sampler.Suppressed())
+}
+
+func TestNewSamplerDefaults(t *testing.T) {
+	sampler := NewSampler(-1, -1, 0)
+	assert.Equal(t, DefaultSamplingInterval, sampler.interval)
+	assert.Zero(t, sampler.first)
+	assert.Zero(t, sampler.thereafter)
+}
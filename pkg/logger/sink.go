@@ -0,0 +1,286 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"sync/atomic"
+)
+
+// Sink is a destination of log messages with its own minimum level and encoder,
+// such as the console, a JSON file or an error-only stream. A logger created
+// with WithSinks writes every message to all sinks whose level it reaches.
+//
+// # Failure Isolation
+//
+// By default, a sink queues its messages and writes them in a background
+// goroutine through an AsyncWriter, so that a slow writer holds up neither the
+// logging goroutine nor the other sinks; when the queue is full, messages are
+// dropped rather than blocking (see Dropped and WithSinkAsync). A sink whose
+// writer fails or panics does not keep the message from reaching the other
+// sinks: the error is counted (see Errors) and passed to the function set with
+// WithSinkErrorHandler.
+//
+// Close sinks during shutdown, so that the queued messages are written. Fatal
+// writes the queued messages of the logger's sinks before exiting, after the
+// exit hooks have run. WithSinkSync makes a sink write in the logging goroutine
+// instead, like a plain log.Logger.
+//
+// # Example
+//
+//	reportError := func(sink string, err error) { fmt.Fprintf(os.Stderr, "log sink %s: %v\n", sink, err) }
+//	console := logger.NewSink("console", os.Stdout, logger.WithSinkFlags(log.LstdFlags))
+//	file := logger.NewSink("file", jsonFile,
+//	    logger.WithSinkLevel(logger.LevelDebug),
+//	    logger.WithSinkEncoder(logger.JSONEncoder{}),
+//	    logger.WithSinkAsync(logger.WithQueueSize(4096)),
+//	    logger.WithSinkErrorHandler(reportError),
+//	)
+//	errorsOnly := logger.NewSink("stderr", os.Stderr, logger.WithSinkLevel(logger.LevelError))
+//	defer func() {
+//	    for _, s := range []*logger.Sink{console, file, errorsOnly} {
+//	        s.Close(ctx)
+//	    }
+//	}()
+//
+//	ctxLogger := logger.NewContextLogger(nil,
+//	    logger.WithLevel(logger.LevelDebug),
+//	    logger.WithSinks(console, file, errorsOnly),
+//	)
+//
+// A Sink is safe for concurrent use by multiple goroutines and may be shared by
+// several loggers.
+type Sink struct {
+	name    string
+	level   *AtomicLevel
+	encoder Encoder
+	flags   int
+	onError func(sink string, err error)
+	// synchronous makes the sink write directly instead of through an
+	// AsyncWriter configured with asyncOpts.
+	synchronous bool
+	asyncOpts   []AsyncOption
+
+	// logger renders the prefix and flags, and writes to the writer or async.
+	logger *log.Logger
+	async  *AsyncWriter
+	errors atomic.Uint64
+}
+
+// SinkOption configures a Sink created by NewSink.
+type SinkOption func(*Sink)
+
+// WithSinkLevel sets the minimum level of the messages written to the sink. The
+// default is LevelInfo. The logger's own minimum level is applied first.
+func WithSinkLevel(level Level) SinkOption {
+	return func(s *Sink) { s.level = NewAtomicLevel(level) }
+}
+
+// WithSinkAtomicLevel makes the sink use the given AtomicLevel as its minimum
+// level, so that it can be changed at runtime.
+func WithSinkAtomicLevel(level *AtomicLevel) SinkOption {
+	return func(s *Sink) {
+		if level != nil {
+			s.level = level
+		}
+	}
+}
+
+// WithSinkEncoder sets the encoder that renders the messages written to the
+// sink. The default is TextEncoder.
+func WithSinkEncoder(encoder Encoder) SinkOption {
+	return func(s *Sink) {
+		if encoder != nil {
+			s.encoder = encoder
+		}
+	}
+}
+
+// WithSinkFlags sets the flags of the sink's lines, as for log.New, such as
+// log.LstdFlags to prefix each line with the date and time. The default is 0,
+// which suits JSONEncoder and LogfmtEncoder.
+func WithSinkFlags(flag int) SinkOption {
+	return func(s *Sink) { s.flags = flag }
+}
+
+// WithSinkAsync configures the AsyncWriter through which the sink writes its
+// messages in the background, such as its queue size and drop policy. The
+// sink's error handler is installed as the AsyncWriter's error handler. It
+// undoes WithSinkSync.
+func WithSinkAsync(opts ...AsyncOption) SinkOption {
+	return func(s *Sink) {
+		s.synchronous = false
+		s.asyncOpts = opts
+	}
+}
+
+// WithSinkSync makes the sink write its messages in the logging goroutine, so
+// that they are written when the logging call returns. A slow writer then holds
+// up the caller and the sinks after it.
+func WithSinkSync() SinkOption {
+	return func(s *Sink) { s.synchronous = true }
+}
+
+// WithSinkErrorHandler sets a function that is called with the sink's name and
+// the error of every failed write. It must not log to a logger that writes to
+// the failing sink.
+func WithSinkErrorHandler(handle func(sink string, err error)) SinkOption {
+	return func(s *Sink) { s.onError = handle }
+}
+
+// NewSink creates a sink that writes to w.
+//
+// # Parameters
+//
+// - name: The name of the sink, passed to the error handler, e.g. "file".
+//
+// - w: The writer that receives the encoded messages, one per line.
+//
+// - opts: Optional settings such as WithSinkLevel, WithSinkEncoder, WithSinkAsync and WithSinkErrorHandler.
+//
+// # Return Value
+//
+// - *Sink: The sink, to be attached to loggers with WithSinks. Close it when it is no longer used.
+func NewSink(name string, w io.Writer, opts ...SinkOption) *Sink {
+	s := &Sink{
+		name:    name,
+		level:   NewAtomicLevel(LevelInfo),
+		encoder: TextEncoder{},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if !s.synchronous {
+		asyncOpts := append(slices.Clip(s.asyncOpts), WithErrorHandler(s.handleError))
+		s.async = NewAsyncWriter(w, asyncOpts...)
+		w = s.async
+	}
+	s.logger = log.New(w, "", s.flags)
+	return s
+}
+
+// WithSinks makes the logger write its messages to the given sinks, each at its
+// own level and with its own encoder, instead of the underlying log.Logger. The
+// logger's minimum level is applied first, so set it to the lowest sink level.
+// A logger with a slog.Handler sends its messages to the handler instead.
+//
+// Sinks write in the background unless created with WithSinkSync; Close them
+// during shutdown. Fatal writes their queued messages before exiting. Without
+// sinks, including an empty slice, the logger writes to the underlying
+// log.Logger as usual.
+func WithSinks(sinks ...*Sink) Option {
+	return func(l *ContextLogger) {
+		l.sinks = nil
+		if len(sinks) > 0 {
+			l.sinks = slices.Clone(sinks)
+		}
+	}
+}
+
+// Name returns the name of the sink.
+func (s *Sink) Name() string {
+	return s.name
+}
+
+// Enabled reports whether messages of the given level are written to the sink.
+func (s *Sink) Enabled(level Level) bool {
+	return s.level.Enabled(level)
+}
+
+// AtomicLevel returns the handle of the sink's minimum level.
+func (s *Sink) AtomicLevel() *AtomicLevel {
+	return s.level
+}
+
+// Errors returns the number of writes to the sink that failed.
+func (s *Sink) Errors() uint64 {
+	return s.errors.Load()
+}
+
+// Dropped returns the number of messages the sink discarded because its queue
+// was full. It is zero for synchronous sinks.
+func (s *Sink) Dropped() uint64 {
+	if s.async == nil {
+		return 0
+	}
+	return s.async.Dropped()
+}
+
+// Close writes the queued messages of the sink, waiting until they are written
+// or ctx is done, and stops its background goroutine. It does not close the
+// sink's writer, and does nothing for synchronous sinks.
+// Writing to a closed sink fails with ErrClosed, which is counted in Errors.
+//
+// # Return Value
+//
+// - error: ctx.Err() if the context ended before the queue was drained, otherwise nil.
+func (s *Sink) Close(ctx context.Context) error {
+	if s.async == nil {
+		return nil
+	}
+	return s.async.Close(ctx)
+}
+
+// flush waits until the messages queued before the call are written or ctx is
+// done. It does nothing for synchronous sinks.
+func (s *Sink) flush(ctx context.Context) error {
+	if s.async == nil {
+		return nil
+	}
+	return s.async.Flush(ctx)
+}
+
+// write encodes the entry and writes it to the sink. The depth is the call depth
+// of the entry's caller as seen from the caller of write, for the log.Lshortfile
+// and log.Llongfile flags. A panic of the encoder or writer is reported as an
+// error, so that the other sinks still receive the entry.
+func (s *Sink) write(entry *Entry, depth int) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.handleError(fmt.Errorf("logger: sink panicked: %v", r))
+		}
+	}()
+
+	buf := bufferPool.Get().(*bytes.Buffer)
+	defer bufferPool.Put(buf)
+	buf.Reset()
+	encodeEntry(buf, s.encoder, entry)
+
+	// Account for write itself.
+	if err := s.logger.Output(depth+1, buf.String()); err != nil {
+		s.handleError(err)
+	}
+}
+
+// reportsFile reports whether the sink's flags include the file of the caller.
+func (s *Sink) reportsFile() bool {
+	return s.flags&(log.Lshortfile|log.Llongfile) != 0
+}
+
+// handleError counts a failed write and reports it to the error handler.
+func (s *Sink) handleError(err error) {
+	s.errors.Add(1)
+	if s.onError != nil {
+		s.onError(s.name, err)
+	}
+}
+
+// sinksEnabled reports whether any of the logger's sinks, if it has any, writes
+// messages of the given level.
+func (l *ContextLogger) sinksEnabled(level Level) bool {
+	return l.sinks == nil || slices.ContainsFunc(l.sinks, func(s *Sink) bool { return s.Enabled(level) })
+}
+
+// writeSinks writes the entry to every sink whose level it reaches. The depth is
+// the call depth of the entry's caller as seen from the caller of writeSinks.
+func (l *ContextLogger) writeSinks(entry *Entry, depth int) {
+	for _, s := range l.sinks {
+		if s.Enabled(entry.Level) {
+			s.write(entry, depth+1)
+		}
+	}
+}
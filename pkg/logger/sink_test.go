@@ -0,0 +1,274 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sinkErrors records the errors reported by sinks.
+type sinkErrors struct {
+	mu     sync.Mutex
+	errors []string
+}
+
+func (r *sinkErrors) record(sink string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errors = append(r.errors, sink+": "+err.Error())
+}
+
+func (r *sinkErrors) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.errors...)
+}
+
+// panickingWriter is a writer whose writes panic.
+type panickingWriter struct{}
+
+func (panickingWriter) Write(p []byte) (int, error) {
+	panic("broken writer")
+}
+
+// closeSinks closes the sinks, writing their queued messages.
+func closeSinks(t *testing.T, sinks ...*Sink) {
+	t.Helper()
+	for _, s := range sinks {
+		assert.NoError(t, s.Close(context.Background()))
+	}
+}
+
+func TestSinks(t *testing.T) {
+	var console, file, stderr bytes.Buffer
+	consoleSink := NewSink("console", &console)
+	fileSink := NewSink("file", &file, WithSinkLevel(LevelDebug), WithSinkEncoder(JSONEncoder{}))
+	stderrSink := NewSink("stderr", &stderr, WithSinkLevel(LevelError), WithSinkEncoder(LogfmtEncoder{}))
+
+	ctxLogger := NewContextLogger(nil, WithLevel(LevelDebug), WithoutStackTrace(),
+		WithSinks(consoleSink, fileSink, stderrSink))
+	ctx := WithRequestID(context.Background(), "req-123")
+
+	ctxLogger.Debug(ctx, "Loading configuration")
+	ctxLogger.InfoKV(ctx, "Order shipped", "items", 3)
+	ctxLogger.Error(ctx, "Payment failed")
+	closeSinks(t, consoleSink, fileSink, stderrSink)
+
+	assert.Equal(t, "INFO: [request_id=req-123] Order shipped items=3\n"+
+		"ERROR: [request_id=req-123] Payment failed\n", console.String())
+
+	lines := strings.Split(strings.TrimSpace(file.String()), "\n")
+	assert.Len(t, lines, 3, "The file sink should receive debug messages")
+	var decoded map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[1]), &decoded))
+	assert.Equal(t, "INFO", decoded["level"])
+	assert.Equal(t, "Order shipped", decoded["message"])
+	assert.Equal(t, float64(3), decoded["items"])
+
+	assert.Regexp(t, `^time=\S+ level=ERROR msg="Payment failed" request_id=req-123\n$`, stderr.String())
+}
+
+func TestSinksEnabled(t *testing.T) {
+	infoSink := NewSink("info", &bytes.Buffer{}, WithSinkSync())
+	errorSink := NewSink("error", &bytes.Buffer{}, WithSinkLevel(LevelError), WithSinkSync())
+	ctx := context.Background()
+
+	ctxLogger := NewContextLogger(nil, WithLevel(LevelDebug), WithSinks(infoSink, errorSink))
+	assert.False(t, ctxLogger.Enabled(ctx, LevelDebug), "No sink writes debug messages")
+	assert.True(t, ctxLogger.Enabled(ctx, LevelInfo))
+
+	errorsOnly := NewContextLogger(nil, WithSinks(errorSink))
+	assert.False(t, errorsOnly.Enabled(ctx, LevelWarning))
+	assert.True(t, errorsOnly.Enabled(ctx, LevelError))
+
+	errorSink.AtomicLevel().SetLevel(LevelWarning)
+	assert.True(t, errorsOnly.Enabled(ctx, LevelWarning), "Sink levels should be changeable at runtime")
+
+	warnOnly := NewContextLogger(nil, WithLevel(LevelWarning), WithSinks(infoSink))
+	assert.False(t, warnOnly.Enabled(ctx, LevelInfo), "The logger's level should be applied first")
+}
+
+func TestSinkFailureIsolation(t *testing.T) {
+	var console bytes.Buffer
+	var reported sinkErrors
+	failing := NewSink("file", failingWriter{}, WithSinkSync(), WithSinkErrorHandler(reported.record))
+	panicking := NewSink("broken", panickingWriter{}, WithSinkSync(), WithSinkErrorHandler(reported.record))
+	consoleSink := NewSink("console", &console, WithSinkSync())
+
+	ctxLogger := NewContextLogger(nil, WithSinks(failing, panicking, consoleSink))
+	ctxLogger.Info(context.Background(), "Order shipped")
+	ctxLogger.Warning(context.Background(), "Stock low")
+
+	assert.Equal(t, "INFO: Order shipped\nWARNING: Stock low\n", console.String(),
+		"A failing sink should not keep messages from the other sinks")
+	assert.Equal(t, uint64(2), failing.Errors())
+	assert.Equal(t, uint64(2), panicking.Errors())
+	assert.Zero(t, consoleSink.Errors())
+	assert.Equal(t, []string{
+		"file: disk full", "broken: logger: sink panicked: broken writer",
+		"file: disk full", "broken: logger: sink panicked: broken writer",
+	}, reported.list())
+}
+
+func TestAsyncSink(t *testing.T) {
+	slow := newGatedWriter()
+	var console bytes.Buffer
+	var reported sinkErrors
+	slowSink := NewSink("file", slow, WithSinkAsync(WithQueueSize(1)), WithSinkErrorHandler(reported.record))
+	consoleSink := NewSink("console", &console, WithSinkSync())
+	ctxLogger := NewContextLogger(nil, WithSinks(slowSink, consoleSink))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ctxLogger.Info(context.Background(), "Busy")
+		<-slow.started
+		for i := 0; i < 3; i++ {
+			ctxLogger.Info(context.Background(), "Queued")
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("A slow asynchronous sink should not block logging")
+	}
+
+	assert.Equal(t, 4, strings.Count(console.String(), "INFO:"))
+	assert.Equal(t, uint64(2), slowSink.Dropped())
+	assert.Zero(t, consoleSink.Dropped())
+
+	close(slow.gate)
+	assert.NoError(t, slowSink.Close(context.Background()))
+	assert.NoError(t, consoleSink.Close(context.Background()))
+	assert.Equal(t, "INFO: Busy\nINFO: Queued\n", slow.String())
+	assert.Empty(t, reported.list())
+
+	failing := NewSink("file", failingWriter{}, WithSinkErrorHandler(reported.record))
+	panicking := NewSink("broken", panickingWriter{}, WithSinkErrorHandler(reported.record))
+	NewContextLogger(nil, WithSinks(failing, panicking)).Info(context.Background(), "Order shipped")
+	closeSinks(t, failing, panicking)
+	assert.Equal(t, uint64(1), failing.Errors())
+	assert.Equal(t, uint64(1), panicking.Errors())
+	assert.ElementsMatch(t, []string{"file: disk full", "broken: logger: writer panicked: broken writer"}, reported.list(),
+		"Errors of asynchronous writes should be reported too")
+}
+
+func TestSinkSlowWriterDoesNotBlock(t *testing.T) {
+	slow := newGatedWriter()
+	var console bytes.Buffer
+	slowSink := NewSink("file", slow)
+	consoleSink := NewSink("console", &console)
+	ctxLogger := NewContextLogger(nil, WithSinks(slowSink, consoleSink))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			ctxLogger.Info(context.Background(), "Order shipped")
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Sinks should not block on a slow writer by default")
+	}
+
+	closeSinks(t, consoleSink)
+	assert.Equal(t, 10, strings.Count(console.String(), "INFO: Order shipped\n"))
+	close(slow.gate)
+	closeSinks(t, slowSink)
+	assert.Equal(t, 10, strings.Count(slow.String(), "INFO: Order shipped\n"))
+}
+
+func TestSinksFlushedOnFatal(t *testing.T) {
+	var file bytes.Buffer
+	fileSink := NewSink("file", &file, WithSinkEncoder(JSONEncoder{}))
+	defer closeSinks(t, fileSink)
+
+	var code int
+	ctxLogger := NewContextLogger(nil, WithSinks(fileSink), WithExitFunc(func(c int) { code = c }))
+	ctxLogger.Fatal(context.Background(), "Configuration file not found")
+
+	assert.Equal(t, 1, code)
+	assert.Contains(t, file.String(), `"message":"Configuration file not found"`,
+		"Fatal should write the queued messages of the sinks before exiting")
+}
+
+func TestSinksNotRegisteredAsExitHooks(t *testing.T) {
+	exitHooksMu.Lock()
+	before := len(exitHooks)
+	exitHooksMu.Unlock()
+
+	sink := NewSink("file", &bytes.Buffer{})
+	defer closeSinks(t, sink)
+	exitHooksMu.Lock()
+	defer exitHooksMu.Unlock()
+	assert.Len(t, exitHooks, before, "Sinks that are never closed should not stay registered globally")
+}
+
+func TestWithoutSinks(t *testing.T) {
+	var logOutput bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&logOutput, "", 0), WithSinks([]*Sink{}...))
+	ctxLogger.Info(context.Background(), "Order shipped")
+	assert.Equal(t, "INFO: Order shipped\n", logOutput.String(), "An empty list of sinks should not disable the output")
+}
+
+func TestSinkWithLogFlags(t *testing.T) {
+	var console, file bytes.Buffer
+	consoleSink := NewSink("console", &console, WithSinkFlags(log.Lshortfile))
+	fileSink := NewSink("file", &file, WithSinkEncoder(LogfmtEncoder{}))
+	ctxLogger := NewContextLogger(nil, WithSinks(consoleSink, fileSink))
+
+	ctxLogger.With(String("order", "ord-789")).Info(context.Background(), "Order shipped")
+	want := strings.TrimPrefix(here(-1), "logger/")
+	closeSinks(t, consoleSink, fileSink)
+
+	assert.Equal(t, want+": INFO: Order shipped order=ord-789\n", console.String(),
+		"log.Lshortfile should report the caller, not sink.go")
+	assert.Regexp(t, `^time=\S+ level=INFO msg="Order shipped" order=ord-789\n$`, file.String())
+}
+
+func TestSinksSharedByLoggers(t *testing.T) {
+	var output bytes.Buffer
+	sink := NewSink("console", &output, WithSinkLevel(LevelDebug))
+	debugLogger := NewContextLogger(nil, WithLevel(LevelDebug), WithSinks(sink))
+	infoLogger := NewContextLogger(nil, WithSinks(sink))
+
+	var wg sync.WaitGroup
+	for _, l := range []*ContextLogger{debugLogger, infoLogger, debugLogger.With(String("worker", "1"))} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				l.Debug(context.Background(), "Tick")
+				l.Info(context.Background(), "Tock")
+			}
+		}()
+	}
+	wg.Wait()
+	closeSinks(t, sink)
+
+	assert.Equal(t, 100, strings.Count(output.String(), "DEBUG: Tick"))
+	assert.Equal(t, 150, strings.Count(output.String(), "INFO: Tock"))
+	assert.Equal(t, "sink", NewSink("sink", &output, WithSinkSync()).Name())
+	assert.Equal(t, uint64(0), sink.Errors())
+}
+
+func TestSinkErrorsWithoutHandler(t *testing.T) {
+	sink := NewSink("file", failingWriter{})
+	ctxLogger := NewContextLogger(nil, WithSinks(sink))
+	ctxLogger.Error(context.Background(), "Payment failed: %v", errors.New("declined"))
+	closeSinks(t, sink)
+	assert.Equal(t, uint64(1), sink.Errors())
+
+	ctxLogger.Error(context.Background(), "Payment failed")
+	assert.Equal(t, uint64(2), sink.Errors(), "Writing to a closed sink should count as an error")
+}
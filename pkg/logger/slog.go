@@ -0,0 +1,181 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+// WithSlogHandler makes the logger send its messages to the given slog.Handler
+// instead of encoding them and writing them to the underlying log.Logger. The
+// handler's Enabled method is consulted in addition to the logger's minimum
+// level. Context values such as request_id and user_id are added as attributes,
+// followed by the structured fields.
+//
+// # Example
+//
+//	jsonHandler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})
+//	ctxLogger := logger.NewContextLogger(nil, logger.WithSlogHandler(jsonHandler))
+//	ctxLogger.Info(ctx, "Processing request")
+//	// Output: {"time":"...","level":"INFO","msg":"Processing request","request_id":"req-123"}
+func WithSlogHandler(handler slog.Handler) Option {
+	return func(l *ContextLogger) { l.handler = handler }
+}
+
+// contextOrBackground returns ctx, or context.Background() if ctx is nil, because
+// slog handlers may not expect a nil context.
+func contextOrBackground(ctx context.Context) context.Context {
+	if ctx == nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+// entryRecord converts an entry to an slog.Record. The caller becomes the
+// record's PC, and the stack trace, if any, the "stack" attribute.
+func entryRecord(e *Entry) slog.Record {
+	r := slog.NewRecord(e.Time, slog.Level(e.Level), e.Message, e.pc)
+	for _, fields := range [][]Field{e.Context, e.Fields} {
+		for _, f := range fields {
+			r.AddAttrs(slog.Any(f.Key, f.Value))
+		}
+	}
+	if e.Stack != "" {
+		r.AddAttrs(slog.String("stack", e.Stack))
+	}
+	return r
+}
+
+// SlogHandler is an slog.Handler that writes records through a ContextLogger, so
+// that code using log/slog shares the logger's level, encoder and output. Values
+// such as request_id and user_id are extracted from the context passed to the
+// slog methods, exactly as for the ContextLogger methods.
+//
+// # Example
+//
+//	slog.SetDefault(slog.New(logger.NewSlogHandler(logger.DefaultLogger)))
+//	slog.InfoContext(logger.WithRequestID(ctx, "req-123"), "Order shipped", "items", 3)
+//	// Output: INFO: [request_id=req-123] Order shipped items=3
+//
+// Attributes inside groups are flattened into fields with dotted keys, such as
+// "http.method".
+type SlogHandler struct {
+	logger *ContextLogger
+	// fields are the attributes added with WithAttrs.
+	fields []Field
+	// prefix is the dotted group path added with WithGroup, e.g. "http.".
+	prefix string
+}
+
+// NewSlogHandler creates an slog.Handler that writes records through l. If l is
+// nil, DefaultLogger is used.
+func NewSlogHandler(l *ContextLogger) *SlogHandler {
+	if l == nil {
+		l = DefaultLogger
+	}
+	return &SlogHandler{logger: l}
+}
+
+// Enabled reports whether the logger logs records of the given level.
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.logger.Enabled(ctx, Level(level))
+}
+
+// Handle writes the record through the logger.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := slices.Clip(h.fields)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttr(fields, h.prefix, a)
+		return true
+	})
+
+	entry := &Entry{Time: r.Time, Level: Level(r.Level), Message: r.Message, pc: r.PC}
+	h.logger.writeEntry(ctx, entry, fields)
+	return nil
+}
+
+// WithAttrs returns a handler that includes the given attributes in every record.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	child := *h
+	child.fields = slices.Clip(h.fields)
+	for _, a := range attrs {
+		child.fields = appendAttr(child.fields, h.prefix, a)
+	}
+	return &child
+}
+
+// WithGroup returns a handler that qualifies the keys of subsequent attributes
+// with the group name.
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	child := *h
+	child.prefix = h.prefix + name + "."
+	return &child
+}
+
+// appendAttr converts an attribute to fields, flattening groups into dotted keys,
+// and appends them to fields. Empty attributes are ignored, as slog requires.
+func appendAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		groupPrefix := prefix
+		if a.Key != "" {
+			groupPrefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			fields = appendAttr(fields, groupPrefix, ga)
+		}
+		return fields
+	}
+	return append(fields, Field{Key: prefix + a.Key, Value: a.Value.Any()})
+}
+
+// ContextHandler is an slog.Handler that adds the values stored in the context,
+// such as request_id and user_id, as attributes before passing records on to
+// another handler. Use it to get the same context information from plain slog
+// handlers as from ContextLogger.
+//
+// # Example
+//
+//	handler := logger.NewContextHandler(slog.NewJSONHandler(os.Stdout, nil))
+//	slog.New(handler).InfoContext(logger.WithRequestID(ctx, "req-123"), "Order shipped")
+//	// Output: {"time":"...","level":"INFO","msg":"Order shipped","request_id":"req-123"}
+type ContextHandler struct {
+	next slog.Handler
+}
+
+// NewContextHandler creates a ContextHandler that passes records on to next.
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{next: next}
+}
+
+// Enabled reports whether the next handler handles records of the given level.
+func (h *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle adds the context values to the record and passes it on.
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if fields := contextFields(ctx); len(fields) > 0 {
+		r = r.Clone()
+		for _, f := range fields {
+			r.AddAttrs(slog.Any(f.Key, f.Value))
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a ContextHandler whose next handler includes the attributes.
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{next: h.next.WithAttrs(attrs)}
+}
+
+// WithGroup returns a ContextHandler whose next handler uses the group.
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: h.next.WithGroup(name)}
+}
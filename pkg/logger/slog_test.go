@@ -0,0 +1,132 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"testing"
+	"testing/slogtest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&buf, "", 0), WithLevel(LevelDebug))
+	slogger := slog.New(NewSlogHandler(ctxLogger))
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	slogger.InfoContext(ctx, "Order shipped", "items", 3)
+	assert.Equal(t, "INFO: [request_id=req-123] Order shipped items=3\n", buf.String())
+
+	// Attributes and groups are flattened into dotted keys.
+	buf.Reset()
+	slogger.With("order", "ord-789").WithGroup("http").DebugContext(ctx, "Request",
+		"method", "GET", slog.Group("peer", "ip", "10.0.0.1"), slog.Group("empty"))
+	assert.Equal(t, "DEBUG: [request_id=req-123] Request order=ord-789 http.method=GET http.peer.ip=10.0.0.1\n", buf.String())
+
+	// Handlers derived from one another do not share attributes.
+	buf.Reset()
+	base := slogger.With("a", 1)
+	_ = base.With("b", 2)
+	base.Info("Message", "c", 3)
+	assert.Equal(t, "INFO: Message a=1 c=3\n", buf.String())
+}
+
+func TestSlogHandlerSharesLevel(t *testing.T) {
+	var buf bytes.Buffer
+	ctxLogger := NewContextLogger(log.New(&buf, "", 0))
+	slogger := slog.New(NewSlogHandler(ctxLogger))
+
+	slogger.Debug("Hidden")
+	assert.Empty(t, buf.String())
+
+	ctxLogger.SetLevel(LevelDebug)
+	slogger.Debug("Shown")
+	assert.Equal(t, "DEBUG: Shown\n", buf.String())
+
+	ctxLogger.SetLevel(LevelError)
+	slogger.Warn("Hidden")
+	assert.Equal(t, "DEBUG: Shown\n", buf.String())
+}
+
+func TestSlogHandlerDefaultLogger(t *testing.T) {
+	assert.Same(t, DefaultLogger, NewSlogHandler(nil).logger)
+}
+
+func TestWithSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	jsonHandler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	ctxLogger := NewContextLogger(nil, WithSlogHandler(jsonHandler)).With(String("order", "ord-789"))
+
+	ctx := WithUserID(WithRequestID(context.Background(), "req-123"), "user-456")
+	ctxLogger.InfoKV(ctx, "Order shipped", "items", 3)
+	assert.JSONEq(t, `{"level":"INFO","msg":"Order shipped","request_id":"req-123","user_id":"user-456","order":"ord-789","items":3}`, buf.String())
+
+	// Debug is below the logger's minimum level, even though the handler accepts it.
+	buf.Reset()
+	ctxLogger.Debug(ctx, "Hidden")
+	assert.Empty(t, buf.String())
+
+	// The handler's own level is consulted too.
+	warnLogger := NewContextLogger(nil, WithLevel(LevelDebug),
+		WithSlogHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	assert.False(t, warnLogger.Enabled(ctx, LevelInfo))
+	assert.True(t, warnLogger.Enabled(ctx, LevelWarning))
+
+	// A nil context is replaced with context.Background().
+	ctxLogger.Warning(nil, "No context")
+	var got map[string]any
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, "WARN", got["level"])
+	assert.Equal(t, "No context", got["msg"])
+}
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewContextHandler(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	slogger := slog.New(handler).With("service", "greeter")
+
+	ctx := WithRequestID(context.Background(), "req-123")
+	slogger.InfoContext(ctx, "Order shipped")
+	assert.JSONEq(t, `{"level":"INFO","msg":"Order shipped","service":"greeter","request_id":"req-123"}`, buf.String())
+
+	buf.Reset()
+	slogger.WithGroup("order").InfoContext(ctx, "Order shipped", "id", "ord-789")
+	assert.JSONEq(t, `{"level":"INFO","msg":"Order shipped","service":"greeter","order":{"id":"ord-789","request_id":"req-123"}}`, buf.String())
+
+	buf.Reset()
+	slogger.DebugContext(ctx, "Hidden")
+	assert.Empty(t, buf.String())
+}
+
+func TestContextHandlerConformance(t *testing.T) {
+	var buf bytes.Buffer
+	newHandler := func(*testing.T) slog.Handler {
+		buf.Reset()
+		return NewContextHandler(slog.NewJSONHandler(&buf, nil))
+	}
+	result := func(t *testing.T) map[string]any {
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &m))
+		return m
+	}
+	slogtest.Run(t, newHandler, result)
+}
@@ -0,0 +1,445 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Header names of the W3C Trace Context specification
+// (https://www.w3.org/TR/trace-context/).
+const (
+	// TraceparentHeader carries the trace ID, parent span ID and trace flags.
+	TraceparentHeader = "traceparent"
+	// TracestateHeader carries vendor-specific trace information.
+	TracestateHeader = "tracestate"
+)
+
+// TraceID identifies a trace, i.e. all the work done for one request across
+// services. It is rendered as 32 lowercase hexadecimal digits.
+type TraceID [16]byte
+
+// NewTraceID returns a random trace ID.
+func NewTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+// IsValid reports whether the trace ID is not all zeros, which the specification
+// reserves as invalid.
+func (id TraceID) IsValid() bool {
+	return id != TraceID{}
+}
+
+// String returns the trace ID as 32 lowercase hexadecimal digits.
+func (id TraceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// SpanID identifies a span, i.e. one operation within a trace. It is rendered as
+// 16 lowercase hexadecimal digits.
+type SpanID [8]byte
+
+// NewSpanID returns a random span ID.
+func NewSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+// IsValid reports whether the span ID is not all zeros, which the specification
+// reserves as invalid.
+func (id SpanID) IsValid() bool {
+	return id != SpanID{}
+}
+
+// String returns the span ID as 16 lowercase hexadecimal digits.
+func (id SpanID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// TraceFlags are the flags of a traceparent header.
+type TraceFlags byte
+
+// FlagSampled indicates that the caller may have recorded the trace.
+const FlagSampled TraceFlags = 0x01
+
+// IsSampled reports whether the sampled flag is set.
+func (f TraceFlags) IsSampled() bool {
+	return f&FlagSampled != 0
+}
+
+// TraceContext is the W3C trace context of an operation: the trace it belongs to,
+// the ID of its span, the trace flags and the vendor-specific trace state.
+//
+// Store a TraceContext in the context with WithTraceContext; the logger then adds
+// trace_id and span_id to every message, so that log lines can be correlated with
+// the traces of other services.
+type TraceContext struct {
+	// TraceID is the ID of the trace.
+	TraceID TraceID
+	// SpanID is the ID of the current span. In a context parsed from an incoming
+	// traceparent header, it is the ID of the caller's span.
+	SpanID SpanID
+	// Flags are the trace flags, such as FlagSampled.
+	Flags TraceFlags
+	// State is the vendor-specific trace state.
+	State TraceState
+}
+
+// NewTraceContext starts a new, sampled trace with a random trace and span ID.
+func NewTraceContext() TraceContext {
+	return TraceContext{TraceID: NewTraceID(), SpanID: NewSpanID(), Flags: FlagSampled}
+}
+
+// IsValid reports whether both the trace and the span ID are valid.
+func (tc TraceContext) IsValid() bool {
+	return tc.TraceID.IsValid() && tc.SpanID.IsValid()
+}
+
+// Child returns the trace context of a new span in the same trace, with a random
+// span ID and the same flags and state.
+//
+// # Example
+//
+//	parent, err := logger.ParseTraceparent(r.Header.Get(logger.TraceparentHeader))
+//	if err == nil {
+//	    ctx = logger.WithTraceContext(ctx, parent.Child())
+//	}
+func (tc TraceContext) Child() TraceContext {
+	tc.SpanID = NewSpanID()
+	return tc
+}
+
+// Traceparent returns the traceparent header value of the trace context, e.g.
+// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func (tc TraceContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", tc.TraceID, tc.SpanID, byte(tc.Flags))
+}
+
+// ParseTraceparent parses a traceparent header value.
+//
+// Version 00 values must match the specification exactly. Values of later
+// versions are accepted if they start with a valid version 00 value, as the
+// specification requires for forward compatibility; the additional data is
+// ignored.
+//
+// # Parameters
+//
+// - s: The header value, e.g. "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+//
+// # Return Values
+//
+// - TraceContext: The parsed trace context, without trace state.
+//
+// - error: An error if the value is malformed or the trace or span ID is all zeros.
+func ParseTraceparent(s string) (TraceContext, error) {
+	const length = 55 // "00-" + 32 + "-" + 16 + "-" + 2
+
+	if len(s) < length || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return TraceContext{}, fmt.Errorf("malformed traceparent %q", s)
+	}
+	version, ok := parseHex(s[0:2])
+	if !ok || version == 0xff {
+		return TraceContext{}, fmt.Errorf("invalid traceparent version in %q", s)
+	}
+	if len(s) > length && (version == 0 || s[length] != '-') {
+		return TraceContext{}, fmt.Errorf("malformed traceparent %q", s)
+	}
+
+	var tc TraceContext
+	if !decodeHex(tc.TraceID[:], s[3:35]) || !tc.TraceID.IsValid() {
+		return TraceContext{}, fmt.Errorf("invalid trace ID in traceparent %q", s)
+	}
+	if !decodeHex(tc.SpanID[:], s[36:52]) || !tc.SpanID.IsValid() {
+		return TraceContext{}, fmt.Errorf("invalid parent ID in traceparent %q", s)
+	}
+	flags, ok := parseHex(s[53:55])
+	if !ok {
+		return TraceContext{}, fmt.Errorf("invalid trace flags in traceparent %q", s)
+	}
+	tc.Flags = TraceFlags(flags)
+	return tc, nil
+}
+
+// isLowerHex reports whether s consists of lowercase hexadecimal digits only; the
+// specification does not allow uppercase digits.
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeHex decodes lowercase hexadecimal digits into dst.
+func decodeHex(dst []byte, s string) bool {
+	if !isLowerHex(s) {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+// parseHex parses two lowercase hexadecimal digits.
+func parseHex(s string) (byte, bool) {
+	var b [1]byte
+	ok := decodeHex(b[:], s)
+	return b[0], ok
+}
+
+// TraceState is the vendor-specific part of a trace context: an ordered list of
+// up to 32 key=value members, as carried by the tracestate header. The zero value
+// is an empty trace state. A TraceState is immutable; Insert and Delete return
+// modified copies.
+type TraceState struct {
+	members []traceStateMember
+}
+
+// traceStateMember is a key=value member of a TraceState.
+type traceStateMember struct {
+	key, value string
+}
+
+// maxTraceStateMembers is the maximum number of members in a trace state.
+const maxTraceStateMembers = 32
+
+// ParseTraceState parses a tracestate header value, such as
+// "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7". Empty list members are ignored. If
+// the value is invalid, the specification requires the whole header to be
+// discarded.
+//
+// # Return Values
+//
+// - TraceState: The parsed trace state.
+//
+// - error: An error if a member is malformed, a key occurs twice or there are more than 32 members.
+func ParseTraceState(s string) (TraceState, error) {
+	var ts TraceState
+	for _, member := range strings.Split(s, ",") {
+		member = strings.Trim(member, " \t")
+		if member == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(member, "=")
+		if !ok || !validTraceStateKey(key) || !validTraceStateValue(value) {
+			return TraceState{}, fmt.Errorf("invalid tracestate member %q", member)
+		}
+		if _, dup := ts.Get(key); dup {
+			return TraceState{}, fmt.Errorf("duplicate tracestate key %q", key)
+		}
+		if len(ts.members) == maxTraceStateMembers {
+			return TraceState{}, fmt.Errorf("tracestate has more than %d members", maxTraceStateMembers)
+		}
+		ts.members = append(ts.members, traceStateMember{key: key, value: value})
+	}
+	return ts, nil
+}
+
+// validTraceStateKey reports whether key is a simple key, such as "rojo", or a
+// multi-tenant key, such as "tenant@vendor".
+func validTraceStateKey(key string) bool {
+	tenant, system, multiTenant := strings.Cut(key, "@")
+	if !multiTenant {
+		return len(key) <= 256 && validKeyPart(key, false)
+	}
+	return len(tenant) <= 241 && len(system) <= 14 &&
+		validKeyPart(tenant, true) && validKeyPart(system, false)
+}
+
+// validKeyPart reports whether s starts with a lowercase letter (or, if
+// digitFirst is set, a digit) and consists of lowercase letters, digits, and the
+// characters _ - * /.
+func validKeyPart(s string, digitFirst bool) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+		case c >= '0' && c <= '9':
+			if i == 0 && !digitFirst {
+				return false
+			}
+		case c == '_' || c == '-' || c == '*' || c == '/':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// validTraceStateValue reports whether value consists of 1 to 256 printable ASCII
+// characters other than comma and equals sign, and does not end with a space.
+func validTraceStateValue(value string) bool {
+	if value == "" || len(value) > 256 || value[len(value)-1] == ' ' {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; c < 0x20 || c > 0x7e || c == ',' || c == '=' {
+			return false
+		}
+	}
+	return true
+}
+
+// Get returns the value of the member with the given key, and whether it exists.
+func (ts TraceState) Get(key string) (string, bool) {
+	for _, m := range ts.members {
+		if m.key == key {
+			return m.value, true
+		}
+	}
+	return "", false
+}
+
+// Insert returns a copy of the trace state in which the member with the given key
+// has the given value and comes first, as the specification requires for the
+// member of the vendor that updated it. If the trace state is full, the last
+// member is dropped.
+//
+// # Return Values
+//
+// - TraceState: The updated trace state.
+//
+// - error: An error if the key or value is invalid; the trace state is returned unchanged.
+func (ts TraceState) Insert(key, value string) (TraceState, error) {
+	if !validTraceStateKey(key) || !validTraceStateValue(value) {
+		return ts, fmt.Errorf("invalid tracestate member %q", key+"="+value)
+	}
+	members := make([]traceStateMember, 0, len(ts.members)+1)
+	members = append(members, traceStateMember{key: key, value: value})
+	for _, m := range ts.members {
+		if m.key != key {
+			members = append(members, m)
+		}
+	}
+	if len(members) > maxTraceStateMembers {
+		members = members[:maxTraceStateMembers]
+	}
+	return TraceState{members: members}, nil
+}
+
+// Delete returns a copy of the trace state without the member with the given key.
+func (ts TraceState) Delete(key string) TraceState {
+	members := make([]traceStateMember, 0, len(ts.members))
+	for _, m := range ts.members {
+		if m.key != key {
+			members = append(members, m)
+		}
+	}
+	return TraceState{members: members}
+}
+
+// Len returns the number of members.
+func (ts TraceState) Len() int {
+	return len(ts.members)
+}
+
+// String returns the tracestate header value of the trace state.
+func (ts TraceState) String() string {
+	var b strings.Builder
+	for i, m := range ts.members {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(m.key)
+		b.WriteByte('=')
+		b.WriteString(m.value)
+	}
+	return b.String()
+}
+
+// ExtractTraceContext reads the trace context from the traceparent and tracestate
+// headers of an incoming request. An invalid tracestate header is ignored, as the
+// specification requires, but an invalid traceparent header is an error.
+//
+// # Example
+//
+//	func handle(w http.ResponseWriter, r *http.Request) {
+//	    ctx := r.Context()
+//	    if parent, err := logger.ExtractTraceContext(r.Header); err == nil {
+//	        ctx = logger.WithTraceContext(ctx, parent.Child())
+//	    }
+//	    logger.DefaultLogger.Info(ctx, "Handling request")
+//	    // Output: INFO: [trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=53995c3f42cd8ad8] Handling request
+//	}
+func ExtractTraceContext(h http.Header) (TraceContext, error) {
+	values := h.Values(TraceparentHeader)
+	if len(values) != 1 {
+		return TraceContext{}, fmt.Errorf("expected one %s header, got %d", TraceparentHeader, len(values))
+	}
+	tc, err := ParseTraceparent(values[0])
+	if err != nil {
+		return TraceContext{}, err
+	}
+	if state, err := ParseTraceState(strings.Join(h.Values(TracestateHeader), ",")); err == nil {
+		tc.State = state
+	}
+	return tc, nil
+}
+
+// InjectTraceContext sets the traceparent and tracestate headers of an outgoing
+// request, so that the called service continues the trace. The tracestate header
+// is omitted if the trace state is empty.
+func InjectTraceContext(h http.Header, tc TraceContext) {
+	h.Set(TraceparentHeader, tc.Traceparent())
+	if tc.State.Len() > 0 {
+		h.Set(TracestateHeader, tc.State.String())
+	} else {
+		h.Del(TracestateHeader)
+	}
+}
+
+// WithTraceContext returns a new context with the given trace context. Messages
+// logged with the returned context include its trace_id and span_id.
+//
+// # Example
+//
+//	ctx = logger.WithTraceContext(ctx, logger.NewTraceContext())
+//	logger.DefaultLogger.Info(ctx, "Processing request")
+//	// Output: INFO: [trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7] Processing request
+func WithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, TraceContextKey, tc)
+}
+
+// TraceContextFromContext returns the trace context stored in ctx, and whether
+// there is a valid one.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	if ctx == nil {
+		return TraceContext{}, false
+	}
+	tc, ok := ctx.Value(TraceContextKey).(TraceContext)
+	return tc, ok && tc.IsValid()
+}
+
+// formatTraceID is the formatter of the trace_id extractor.
+func formatTraceID(value any) (string, bool) {
+	tc, ok := value.(TraceContext)
+	if !ok || !tc.IsValid() {
+		return "", false
+	}
+	return tc.TraceID.String(), true
+}
+
+// formatSpanID is the formatter of the span_id extractor.
+func formatSpanID(value any) (string, bool) {
+	tc, ok := value.(TraceContext)
+	if !ok || !tc.IsValid() {
+		return "", false
+	}
+	return tc.SpanID.String(), true
+}
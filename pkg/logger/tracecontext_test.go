@@ -0,0 +1,201 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestParseTraceparent(t *testing.T) {
+	tc, err := ParseTraceparent(testTraceparent)
+	assert.NoError(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", tc.TraceID.String())
+	assert.Equal(t, "00f067aa0ba902b7", tc.SpanID.String())
+	assert.True(t, tc.Flags.IsSampled())
+	assert.Equal(t, testTraceparent, tc.Traceparent())
+
+	tc, err = ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	assert.NoError(t, err)
+	assert.False(t, tc.Flags.IsSampled())
+
+	// Later versions may append fields.
+	tc, err = ParseTraceparent("cc-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-what-the-future-holds")
+	assert.NoError(t, err)
+	assert.Equal(t, testTraceparent, tc.Traceparent())
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"empty", ""},
+		{"too short", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-1"},
+		{"trailing data in version 00", testTraceparent + "-extra"},
+		{"trailing data without separator", "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01x"},
+		{"forbidden version", "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{"uppercase", "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+		{"wrong separator", "00_4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{"zero trace ID", "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{"zero span ID", "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{"invalid flags", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-0x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseTraceparent(tt.value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewTraceContext(t *testing.T) {
+	tc := NewTraceContext()
+	assert.True(t, tc.IsValid())
+	assert.True(t, tc.Flags.IsSampled())
+	assert.NotEqual(t, NewTraceContext().TraceID, tc.TraceID)
+
+	parsed, err := ParseTraceparent(tc.Traceparent())
+	assert.NoError(t, err)
+	assert.Equal(t, tc, parsed)
+
+	child := tc.Child()
+	assert.Equal(t, tc.TraceID, child.TraceID)
+	assert.NotEqual(t, tc.SpanID, child.SpanID)
+	assert.Equal(t, tc.Flags, child.Flags)
+}
+
+func TestParseTraceState(t *testing.T) {
+	ts, err := ParseTraceState("congo=t61rcWkgMzE, rojo=00f067aa0ba902b7,,tenant@vendor=x")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, ts.Len())
+	assert.Equal(t, "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7,tenant@vendor=x", ts.String())
+	value, ok := ts.Get("rojo")
+	assert.True(t, ok)
+	assert.Equal(t, "00f067aa0ba902b7", value)
+	_, ok = ts.Get("missing")
+	assert.False(t, ok)
+
+	ts, err = ParseTraceState("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, ts.Len())
+
+	for _, value := range []string{
+		"congo",
+		"Congo=1",
+		"1congo=1",
+		"congo=",
+		"congo=a,b",
+		"congo=1,congo=2",
+		"congo=t\x01",
+		"@vendor=1",
+		"tenant@=1",
+	} {
+		_, err := ParseTraceState(value)
+		assert.Error(t, err, value)
+	}
+
+	members := make([]string, 33)
+	for i := range members {
+		members[i] = testTraceStateKey(i) + "=1"
+	}
+	ts, err = ParseTraceState(strings.Join(members[:32], ","))
+	assert.NoError(t, err)
+	assert.Equal(t, 32, ts.Len())
+	_, err = ParseTraceState(strings.Join(members, ","))
+	assert.Error(t, err, "More than 32 members")
+}
+
+// testTraceStateKey returns a distinct valid trace state key for each i.
+func testTraceStateKey(i int) string {
+	return "k" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+}
+
+func TestTraceStateInsertDelete(t *testing.T) {
+	ts, _ := ParseTraceState("congo=t61rcWkgMzE,rojo=00f067aa0ba902b7")
+
+	updated, err := ts.Insert("rojo", "53995c3f42cd8ad8")
+	assert.NoError(t, err)
+	assert.Equal(t, "rojo=53995c3f42cd8ad8,congo=t61rcWkgMzE", updated.String())
+	assert.Equal(t, "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7", ts.String(), "Insert must not modify the receiver")
+
+	_, err = ts.Insert("Rojo", "1")
+	assert.Error(t, err)
+
+	assert.Equal(t, "congo=t61rcWkgMzE", ts.Delete("rojo").String())
+	assert.Equal(t, ts.String(), ts.Delete("missing").String())
+
+	var full TraceState
+	for i := 0; i < 40; i++ {
+		full, err = full.Insert(testTraceStateKey(i), "1")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 32, full.Len())
+	value, ok := full.Get(testTraceStateKey(39))
+	assert.True(t, ok, "The newest member is kept")
+	assert.Equal(t, "1", value)
+	_, ok = full.Get(testTraceStateKey(0))
+	assert.False(t, ok, "The oldest member is dropped")
+}
+
+func TestExtractInjectTraceContext(t *testing.T) {
+	h := http.Header{}
+	h.Set(TraceparentHeader, testTraceparent)
+	h.Add(TracestateHeader, "congo=t61rcWkgMzE")
+	h.Add(TracestateHeader, "rojo=00f067aa0ba902b7")
+
+	tc, err := ExtractTraceContext(h)
+	assert.NoError(t, err)
+	assert.Equal(t, testTraceparent, tc.Traceparent())
+	assert.Equal(t, "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7", tc.State.String())
+
+	out := http.Header{}
+	InjectTraceContext(out, tc)
+	assert.Equal(t, testTraceparent, out.Get(TraceparentHeader))
+	assert.Equal(t, "congo=t61rcWkgMzE,rojo=00f067aa0ba902b7", out.Get(TracestateHeader))
+
+	// An invalid tracestate header is discarded.
+	h.Set(TracestateHeader, "invalid")
+	tc, err = ExtractTraceContext(h)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, tc.State.Len())
+	InjectTraceContext(out, tc)
+	assert.Empty(t, out.Values(TracestateHeader))
+
+	// Missing, repeated or invalid traceparent headers are errors.
+	_, err = ExtractTraceContext(http.Header{})
+	assert.Error(t, err)
+	h.Add(TraceparentHeader, testTraceparent)
+	_, err = ExtractTraceContext(h)
+	assert.Error(t, err)
+	h.Set(TraceparentHeader, "invalid")
+	_, err = ExtractTraceContext(h)
+	assert.Error(t, err)
+}
+
+func TestWithTraceContext(t *testing.T) {
+	_, ok := TraceContextFromContext(nil)
+	assert.False(t, ok)
+	_, ok = TraceContextFromContext(context.Background())
+	assert.False(t, ok)
+	_, ok = TraceContextFromContext(WithTraceContext(context.Background(), TraceContext{}))
+	assert.False(t, ok, "Invalid trace contexts are not reported")
+
+	tc, _ := ParseTraceparent(testTraceparent)
+	ctx := WithTraceContext(WithRequestID(context.Background(), "req-123"), tc)
+	got, ok := TraceContextFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, tc, got)
+
+	var buf bytes.Buffer
+	NewContextLogger(log.New(&buf, "", 0)).Info(ctx, "Processing request")
+	assert.Equal(t, "INFO: [request_id=req-123 trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7] Processing request\n", buf.String())
+
+	buf.Reset()
+	NewContextLogger(log.New(&buf, "", 0)).Info(WithTraceContext(context.Background(), TraceContext{}), "No trace")
+	assert.Equal(t, "INFO: No trace\n", buf.String())
+}
@@ -0,0 +1,53 @@
+// Package tracing provides a minimal tracing API for measuring where the time of
+// an operation goes, without depending on a tracing SDK or a live collector.
+//
+// # Overview
+//
+// A trace is made of spans. Each span records one operation: its name, start and
+// end time, attributes, errors and the span it was started from. Spans are
+// created with Start, which takes the parent span from the context, and are
+// passed to an Exporter when they end. The package provides two exporters:
+//
+// - InMemoryExporter keeps the spans in memory, for tests and in-process inspection.
+// - JSONFileExporter appends the spans to a file as newline-delimited JSON.
+//
+// Span and trace IDs follow the W3C Trace Context format of the logger package.
+// The context returned by Start carries the span's logger.TraceContext, so every
+// message logged with it includes the span's trace_id and span_id. A trace
+// context received from another service, for example with
+// logger.ExtractTraceContext, is continued rather than replaced.
+//
+// # Basic Usage
+//
+//	exporter, err := tracing.NewJSONFileExporter("traces.jsonl")
+//	if err != nil {
+//	    log.Fatalf("Error: %v", err)
+//	}
+//	defer exporter.Close()
+//	tracing.DefaultTracer = tracing.NewTracer(exporter)
+//
+//	ctx, span := tracing.Start(ctx, "orders.Ship", tracing.String("order", orderID))
+//	defer span.End()
+//	if err := ship(ctx, order); err != nil {
+//	    span.RecordError(err)
+//	    return err
+//	}
+//
+// # Disabled Tracing
+//
+// DefaultTracer has no exporter, which disables it: Start returns the context
+// unchanged and a nil *Span. All Span methods accept a nil receiver and do
+// nothing, so instrumented code does not need to check whether tracing is
+// enabled, and costs next to nothing when it is not.
+//
+// # Context Convention
+//
+// Start requires a context.Context as its first parameter and returns the
+// context to pass on to the traced operation, following the project's convention
+// that any function requiring context must have it as the first parameter.
+//
+// # Thread Safety
+//
+// Tracers, spans and the provided exporters are safe for concurrent use by
+// multiple goroutines.
+package tracing
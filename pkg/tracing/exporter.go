@@ -0,0 +1,165 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Exporter receives the spans of a Tracer when they end. Implementations must be
+// safe for concurrent use by multiple goroutines, and should return quickly
+// because ExportSpan is called synchronously by Span.End.
+type Exporter interface {
+	// ExportSpan records an ended span.
+	ExportSpan(span SpanData) error
+}
+
+// InMemoryExporter keeps ended spans in memory. It is useful in tests and for
+// inspecting the latency of operations within a process.
+//
+// # Example
+//
+//	exporter := tracing.NewInMemoryExporter()
+//	g, _ := greeting.NewGreeter(greeting.WithTracer(tracing.NewTracer(exporter)))
+//	g.Greet(ctx, "John")
+//	for _, span := range exporter.Spans() {
+//	    fmt.Println(span.Name, span.Duration())
+//	}
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// NewInMemoryExporter creates an empty InMemoryExporter.
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpan implements Exporter.
+func (e *InMemoryExporter) ExportSpan(span SpanData) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+	return nil
+}
+
+// Spans returns the exported spans in the order in which they ended.
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.spans)
+}
+
+// Reset discards the exported spans.
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
+
+// JSONFileExporter appends ended spans to a file as newline-delimited JSON, one
+// object per span:
+//
+//	{"name":"greeting.Greet","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7","start":"2025-01-02T03:04:05.678Z","end":"2025-01-02T03:04:05.779Z","duration_ms":101.2,"status":"OK","attributes":{"greeting.name":"John"}}
+//
+// The parent_span_id member is omitted for root spans, and the events and
+// status_message members when they are empty. Each span is written with a single
+// write call, so the file stays consistent even if the process exits abruptly.
+type JSONFileExporter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewJSONFileExporter creates a JSONFileExporter that appends to the file at
+// path, creating it if necessary. Close the exporter when it is no longer used.
+func NewJSONFileExporter(path string) (*JSONFileExporter, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open trace file: %w", err)
+	}
+	return &JSONFileExporter{file: f}, nil
+}
+
+// jsonSpan is the JSON representation of a span written by JSONFileExporter.
+type jsonSpan struct {
+	Name          string         `json:"name"`
+	TraceID       string         `json:"trace_id"`
+	SpanID        string         `json:"span_id"`
+	ParentSpanID  string         `json:"parent_span_id,omitempty"`
+	Start         time.Time      `json:"start"`
+	End           time.Time      `json:"end"`
+	DurationMS    float64        `json:"duration_ms"`
+	Status        string         `json:"status"`
+	StatusMessage string         `json:"status_message,omitempty"`
+	Attributes    map[string]any `json:"attributes,omitempty"`
+	Events        []jsonEvent    `json:"events,omitempty"`
+}
+
+// jsonEvent is the JSON representation of a span event.
+type jsonEvent struct {
+	Name       string         `json:"name"`
+	Time       time.Time      `json:"time"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+// attributeMap converts attributes to a map for JSON encoding.
+func attributeMap(attrs []Attribute) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(attrs))
+	for _, a := range attrs {
+		m[a.Key] = a.Value
+	}
+	return m
+}
+
+// ExportSpan implements Exporter.
+func (e *JSONFileExporter) ExportSpan(span SpanData) error {
+	js := jsonSpan{
+		Name:          span.Name,
+		TraceID:       span.TraceID.String(),
+		SpanID:        span.SpanID.String(),
+		Start:         span.Start,
+		End:           span.End,
+		DurationMS:    float64(span.Duration()) / float64(time.Millisecond),
+		Status:        span.Status.String(),
+		StatusMessage: span.StatusMessage,
+		Attributes:    attributeMap(span.Attributes),
+	}
+	if span.ParentSpanID.IsValid() {
+		js.ParentSpanID = span.ParentSpanID.String()
+	}
+	for _, ev := range span.Events {
+		js.Events = append(js.Events, jsonEvent{Name: ev.Name, Time: ev.Time, Attributes: attributeMap(ev.Attributes)})
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(js); err != nil {
+		return fmt.Errorf("encode span %s: %w", span.Name, err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.file == nil {
+		return os.ErrClosed
+	}
+	_, err := e.file.Write(buf.Bytes())
+	return err
+}
+
+// Close closes the file. Spans exported afterwards are rejected with os.ErrClosed.
+func (e *JSONFileExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.file == nil {
+		return nil
+	}
+	err := e.file.Close()
+	e.file = nil
+	return err
+}
@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInMemoryExporter(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	assert.Empty(t, exporter.Spans())
+
+	assert.NoError(t, exporter.ExportSpan(SpanData{Name: "a"}))
+	assert.NoError(t, exporter.ExportSpan(SpanData{Name: "b"}))
+	spans := exporter.Spans()
+	assert.Equal(t, []string{"a", "b"}, []string{spans[0].Name, spans[1].Name})
+
+	spans[0].Name = "modified"
+	assert.Equal(t, "a", exporter.Spans()[0].Name, "Spans returns a copy")
+
+	exporter.Reset()
+	assert.Empty(t, exporter.Spans())
+}
+
+func TestJSONFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	exporter, err := NewJSONFileExporter(path)
+	assert.NoError(t, err)
+
+	tracer := NewTracer(exporter, WithClock(testClock()))
+	ctx, root := tracer.Start(context.Background(), "root", String("greeting.name", "John"))
+	_, child := tracer.Start(ctx, "child")
+	child.RecordError(errors.New("boom"))
+	child.End()
+	root.End()
+	assert.NoError(t, exporter.Close())
+	assert.NoError(t, exporter.Close(), "Close is idempotent")
+	assert.ErrorIs(t, exporter.ExportSpan(SpanData{}), os.ErrClosed)
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	assert.Len(t, lines, 2)
+
+	rootData := root.TraceContext()
+	assert.JSONEq(t, `{
+		"name": "root",
+		"trace_id": "`+rootData.TraceID.String()+`",
+		"span_id": "`+rootData.SpanID.String()+`",
+		"start": "2025-01-02T03:04:05.001Z",
+		"end": "2025-01-02T03:04:05.005Z",
+		"duration_ms": 4,
+		"status": "UNSET",
+		"attributes": {"greeting.name": "John"}
+	}`, lines[1])
+
+	var childJSON map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(lines[0]), &childJSON))
+	assert.Equal(t, "child", childJSON["name"])
+	assert.Equal(t, rootData.SpanID.String(), childJSON["parent_span_id"])
+	assert.Equal(t, "ERROR", childJSON["status"])
+	assert.Equal(t, "boom", childJSON["status_message"])
+	assert.Len(t, childJSON["events"], 1)
+
+	// Spans are appended to existing files.
+	exporter, err = NewJSONFileExporter(path)
+	assert.NoError(t, err)
+	assert.NoError(t, exporter.ExportSpan(SpanData{Name: "third"}))
+	assert.NoError(t, exporter.Close())
+	content, _ = os.ReadFile(path)
+	assert.Equal(t, 3, strings.Count(string(content), "\n"))
+}
+
+func TestJSONFileExporterErrors(t *testing.T) {
+	_, err := NewJSONFileExporter(filepath.Join(t.TempDir(), "missing", "traces.jsonl"))
+	assert.Error(t, err)
+
+	exporter, err := NewJSONFileExporter(filepath.Join(t.TempDir(), "traces.jsonl"))
+	assert.NoError(t, err)
+	defer exporter.Close()
+	err = exporter.ExportSpan(SpanData{Name: "op", Attributes: []Attribute{{Key: "f", Value: func() {}}}})
+	assert.ErrorContains(t, err, "encode span op")
+}
@@ -0,0 +1,239 @@
+package tracing
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/abitofhelp/bazel8_go/pkg/logger"
+)
+
+// Attribute is a key/value pair describing a span, such as the name of the
+// greeted person or the locale of the message.
+type Attribute struct {
+	// Key is the name of the attribute, e.g. "greeting.locale".
+	Key string
+	// Value is the value of the attribute.
+	Value any
+}
+
+// String returns an Attribute with a string value.
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int returns an Attribute with an int value.
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int64 returns an Attribute with an int64 value.
+func Int64(key string, value int64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Float64 returns an Attribute with a float64 value.
+func Float64(key string, value float64) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Bool returns an Attribute with a bool value.
+func Bool(key string, value bool) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Status is the outcome of a span.
+type Status int
+
+const (
+	// StatusUnset means that the outcome was not recorded; the operation is
+	// assumed to have succeeded.
+	StatusUnset Status = iota
+	// StatusOK means that the operation succeeded.
+	StatusOK
+	// StatusError means that the operation failed.
+	StatusError
+)
+
+// String returns the name of the status, e.g. "ERROR".
+func (s Status) String() string {
+	switch s {
+	case StatusUnset:
+		return "UNSET"
+	case StatusOK:
+		return "OK"
+	case StatusError:
+		return "ERROR"
+	default:
+		return fmt.Sprintf("Status(%d)", int(s))
+	}
+}
+
+// Event is something that happened at a point in time during a span, such as an
+// error.
+type Event struct {
+	// Name is the name of the event, e.g. "exception".
+	Name string
+	// Time is when the event happened.
+	Time time.Time
+	// Attributes describe the event.
+	Attributes []Attribute
+}
+
+// SpanData is the immutable record of an ended span, as passed to an Exporter.
+type SpanData struct {
+	// Name is the name of the operation, e.g. "greeting.Greet".
+	Name string
+	// TraceID is the ID of the trace the span belongs to.
+	TraceID logger.TraceID
+	// SpanID is the ID of the span.
+	SpanID logger.SpanID
+	// ParentSpanID is the ID of the parent span; it is invalid (all zeros) for the
+	// root span of a trace.
+	ParentSpanID logger.SpanID
+	// Start is when the operation started.
+	Start time.Time
+	// End is when the operation ended.
+	End time.Time
+	// Attributes describe the operation.
+	Attributes []Attribute
+	// Events are the events recorded during the operation, in order.
+	Events []Event
+	// Status is the outcome of the operation.
+	Status Status
+	// StatusMessage describes an error status.
+	StatusMessage string
+}
+
+// Duration returns how long the operation took.
+func (d SpanData) Duration() time.Duration {
+	return d.End.Sub(d.Start)
+}
+
+// Span records a single operation within a trace: its name, timing, attributes
+// and errors. Spans are created with Start or Tracer.Start and must be ended
+// with End, which passes them to the tracer's exporter.
+//
+// All methods may be called on a nil *Span, which is what a disabled tracer
+// returns; they then do nothing. This keeps instrumented code free of checks.
+//
+// A Span is safe for concurrent use by multiple goroutines.
+type Span struct {
+	tracer *Tracer
+	// traceContext identifies the span; it is set by Start and never changes.
+	traceContext logger.TraceContext
+
+	mu    sync.Mutex
+	data  SpanData
+	ended bool
+}
+
+// TraceContext returns the trace context of the span, which is also stored in
+// the context returned by Start, so that log messages include its trace and span
+// ID. It returns the zero TraceContext for a nil span.
+func (s *Span) TraceContext() logger.TraceContext {
+	if s == nil {
+		return logger.TraceContext{}
+	}
+	return s.traceContext
+}
+
+// SetAttributes adds attributes to the span. An attribute replaces an earlier one
+// with the same key. Calls after End are ignored.
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.data.Attributes = setAttributes(s.data.Attributes, attrs)
+}
+
+// setAttributes adds attrs to existing, replacing attributes with the same key.
+func setAttributes(existing, attrs []Attribute) []Attribute {
+	for _, a := range attrs {
+		i := slices.IndexFunc(existing, func(e Attribute) bool { return e.Key == a.Key })
+		if i >= 0 {
+			existing[i] = a
+		} else {
+			existing = append(existing, a)
+		}
+	}
+	return existing
+}
+
+// RecordError records err as an "exception" event and sets the status of the
+// span to StatusError with the error's message. A nil error is ignored, as are
+// calls after End.
+//
+// # Example
+//
+//	ctx, span := tracing.Start(ctx, "orders.Ship")
+//	defer span.End()
+//	if err := ship(ctx, order); err != nil {
+//	    span.RecordError(err)
+//	    return err
+//	}
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	now := s.tracer.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.data.Events = append(s.data.Events, Event{
+		Name: "exception",
+		Time: now,
+		Attributes: []Attribute{
+			String("exception.type", fmt.Sprintf("%T", err)),
+			String("exception.message", err.Error()),
+		},
+	})
+	s.data.Status = StatusError
+	s.data.StatusMessage = err.Error()
+}
+
+// SetStatus sets the outcome of the span. The message is only kept for
+// StatusError. Calls after End are ignored.
+func (s *Span) SetStatus(status Status, message string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	s.data.Status = status
+	s.data.StatusMessage = ""
+	if status == StatusError {
+		s.data.StatusMessage = message
+	}
+}
+
+// End ends the span and passes its record to the tracer's exporter. Only the
+// first call has an effect, so End can be deferred and also called explicitly.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	now := s.tracer.now()
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.End = now
+	data := s.data
+	s.mu.Unlock()
+
+	s.tracer.export(data)
+}
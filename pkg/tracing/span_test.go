@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testClock returns a clock that advances by one millisecond on every call.
+func testClock() func() time.Time {
+	var mu sync.Mutex
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	return func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		now = now.Add(time.Millisecond)
+		return now
+	}
+}
+
+func TestSpanAttributes(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter, WithClock(testClock()))
+
+	_, span := tracer.Start(context.Background(), "op", String("a", "1"), Int("b", 2))
+	span.SetAttributes(Bool("c", true), String("a", "replaced"), Int64("d", 4), Float64("e", 0.5))
+	span.End()
+	span.SetAttributes(String("late", "ignored"))
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, []Attribute{
+		{Key: "a", Value: "replaced"},
+		{Key: "b", Value: 2},
+		{Key: "c", Value: true},
+		{Key: "d", Value: int64(4)},
+		{Key: "e", Value: 0.5},
+	}, spans[0].Attributes)
+	assert.Equal(t, StatusUnset, spans[0].Status)
+	assert.Equal(t, time.Millisecond, spans[0].Duration())
+}
+
+func TestSpanRecordError(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter, WithClock(testClock()))
+
+	_, span := tracer.Start(context.Background(), "op")
+	span.RecordError(nil)
+	err := fmt.Errorf("ship order: %w", errors.New("carrier unavailable"))
+	span.RecordError(err)
+	span.End()
+	span.RecordError(errors.New("late"))
+
+	data := exporter.Spans()[0]
+	assert.Equal(t, StatusError, data.Status)
+	assert.Equal(t, "ship order: carrier unavailable", data.StatusMessage)
+	assert.Len(t, data.Events, 1)
+	assert.Equal(t, "exception", data.Events[0].Name)
+	assert.True(t, data.Events[0].Time.After(data.Start) && data.Events[0].Time.Before(data.End))
+	assert.Equal(t, []Attribute{
+		{Key: "exception.type", Value: "*fmt.wrapError"},
+		{Key: "exception.message", Value: "ship order: carrier unavailable"},
+	}, data.Events[0].Attributes)
+}
+
+func TestSpanSetStatus(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	_, span := tracer.Start(context.Background(), "op")
+	span.SetStatus(StatusError, "failed")
+	span.SetStatus(StatusOK, "ignored")
+	span.End()
+
+	data := exporter.Spans()[0]
+	assert.Equal(t, StatusOK, data.Status)
+	assert.Empty(t, data.StatusMessage)
+}
+
+func TestSpanEndOnce(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	_, span := tracer.Start(context.Background(), "op")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			span.SetAttributes(Int("i", i))
+			span.End()
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, exporter.Spans(), 1)
+}
+
+func TestNilSpan(t *testing.T) {
+	var span *Span
+	assert.NotPanics(t, func() {
+		span.SetAttributes(String("a", "1"))
+		span.RecordError(errors.New("boom"))
+		span.SetStatus(StatusOK, "")
+		span.End()
+	})
+	assert.False(t, span.TraceContext().IsValid())
+}
+
+func TestStatusString(t *testing.T) {
+	assert.Equal(t, "UNSET", StatusUnset.String())
+	assert.Equal(t, "OK", StatusOK.String())
+	assert.Equal(t, "ERROR", StatusError.String())
+	assert.Equal(t, "Status(7)", Status(7).String())
+}
@@ -0,0 +1,179 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/abitofhelp/bazel8_go/pkg/logger"
+)
+
+// spanKey is the context key of the current span.
+type spanKey struct{}
+
+// Tracer creates spans and passes them to an Exporter when they end.
+//
+// # Usage
+//
+// Create a Tracer with NewTracer and either use its Start method or install it
+// as DefaultTracer, which is used by the package-level Start function. A Tracer
+// without an exporter is disabled: Start returns the context unchanged and a nil
+// span, whose methods do nothing, so instrumentation costs next to nothing until
+// an exporter is configured.
+//
+// A Tracer is safe for concurrent use by multiple goroutines.
+type Tracer struct {
+	// exporter receives the ended spans; nil disables the tracer.
+	exporter Exporter
+	// clock returns the current time; the default is time.Now.
+	clock func() time.Time
+	// logger receives export errors; nil means logger.DefaultLogger.
+	logger *logger.ContextLogger
+}
+
+// Option configures a Tracer created by NewTracer.
+type Option func(*Tracer)
+
+// WithClock sets the function that timestamps spans and events, for example to
+// make durations deterministic in tests.
+func WithClock(now func() time.Time) Option {
+	return func(t *Tracer) {
+		if now != nil {
+			t.clock = now
+		}
+	}
+}
+
+// WithLogger sets the logger that receives errors returned by the exporter.
+func WithLogger(l *logger.ContextLogger) Option {
+	return func(t *Tracer) { t.logger = l }
+}
+
+// NewTracer creates a Tracer that passes ended spans to exporter.
+//
+// # Parameters
+//
+// - exporter: The exporter that receives the ended spans. If nil, the tracer is disabled.
+//
+// - opts: Optional settings such as WithClock or WithLogger.
+//
+// # Return Value
+//
+// - *Tracer: The configured tracer.
+//
+// # Example
+//
+//	exporter := tracing.NewInMemoryExporter()
+//	tracer := tracing.NewTracer(exporter)
+//	ctx, span := tracer.Start(ctx, "orders.Ship")
+//	span.End()
+//	fmt.Println(exporter.Spans()[0].Duration())
+func NewTracer(exporter Exporter, opts ...Option) *Tracer {
+	t := &Tracer{exporter: exporter, clock: time.Now}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// DefaultTracer is the tracer used by the package-level Start function. It has
+// no exporter, so tracing is disabled until it is replaced:
+//
+//	tracing.DefaultTracer = tracing.NewTracer(exporter)
+var DefaultTracer = NewTracer(nil)
+
+// Enabled reports whether the tracer records spans, i.e. whether it has an
+// exporter. It is false for a nil tracer.
+func (t *Tracer) Enabled() bool {
+	return t != nil && t.exporter != nil
+}
+
+// Start starts a span and returns it with a context that carries it.
+//
+// The span is a child of the span in ctx, if any. Otherwise it continues the
+// trace of the W3C trace context in ctx (see logger.WithTraceContext), such as
+// one received in a traceparent header, or starts a new trace. The returned
+// context also carries the span's trace context, so that messages logged with it
+// include the span's trace_id and span_id.
+//
+// If the tracer is disabled, Start returns ctx unchanged and a nil span.
+//
+// # Parameters
+//
+// - ctx: The context of the operation.
+//
+// - name: The name of the operation, e.g. "greeting.Greet".
+//
+// - attrs: Optional attributes describing the operation.
+//
+// # Return Values
+//
+// - context.Context: A context carrying the span; pass it to the operation.
+//
+// - *Span: The span, which must be ended with End.
+//
+// # Example
+//
+//	ctx, span := tracer.Start(ctx, "greeting.Greet", tracing.String("greeting.name", name))
+//	defer span.End()
+func (t *Tracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	if !t.Enabled() {
+		return ctx, nil
+	}
+
+	tc := logger.TraceContext{Flags: logger.FlagSampled}
+	if parent := SpanFromContext(ctx); parent != nil {
+		tc = parent.TraceContext()
+	} else if remote, ok := logger.TraceContextFromContext(ctx); ok {
+		tc = remote
+	}
+
+	data := SpanData{
+		Name:         name,
+		TraceID:      tc.TraceID,
+		SpanID:       logger.NewSpanID(),
+		ParentSpanID: tc.SpanID,
+		Start:        t.now(),
+		Attributes:   setAttributes(nil, attrs),
+	}
+	if !data.TraceID.IsValid() {
+		data.TraceID = logger.NewTraceID()
+	}
+	tc.TraceID, tc.SpanID = data.TraceID, data.SpanID
+	span := &Span{tracer: t, traceContext: tc, data: data}
+
+	ctx = context.WithValue(ctx, spanKey{}, span)
+	return logger.WithTraceContext(ctx, tc), span
+}
+
+// Start starts a span with DefaultTracer. See Tracer.Start.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, *Span) {
+	return DefaultTracer.Start(ctx, name, attrs...)
+}
+
+// SpanFromContext returns the span carried by ctx, or nil if there is none.
+// Because the methods of a nil span do nothing, the result can be used directly:
+//
+//	tracing.SpanFromContext(ctx).SetAttributes(tracing.Int("items", 3))
+func SpanFromContext(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+// now returns the current time of the tracer's clock.
+func (t *Tracer) now() time.Time {
+	return t.clock()
+}
+
+// export passes an ended span to the exporter, logging any error.
+func (t *Tracer) export(data SpanData) {
+	if err := t.exporter.ExportSpan(data); err != nil {
+		l := t.logger
+		if l == nil {
+			l = logger.DefaultLogger
+		}
+		l.Error(context.Background(), "Failed to export span %s: %v", data.Name, err)
+	}
+}
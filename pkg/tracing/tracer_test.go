@@ -0,0 +1,103 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/abitofhelp/bazel8_go/pkg/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracerStart(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter, WithClock(testClock()))
+
+	ctx, root := tracer.Start(context.Background(), "root")
+	assert.Same(t, root, SpanFromContext(ctx))
+	childCtx, child := tracer.Start(ctx, "child")
+	assert.Same(t, child, SpanFromContext(childCtx))
+	child.End()
+	root.End()
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "child", spans[0].Name)
+	assert.Equal(t, "root", spans[1].Name)
+	assert.True(t, spans[1].TraceID.IsValid())
+	assert.False(t, spans[1].ParentSpanID.IsValid(), "Root spans have no parent")
+	assert.Equal(t, spans[1].TraceID, spans[0].TraceID)
+	assert.Equal(t, spans[1].SpanID, spans[0].ParentSpanID)
+	assert.NotEqual(t, spans[1].SpanID, spans[0].SpanID)
+
+	// The context carries the span's trace context for the logger.
+	tc, ok := logger.TraceContextFromContext(childCtx)
+	assert.True(t, ok)
+	assert.Equal(t, child.TraceContext(), tc)
+	assert.Equal(t, spans[0].SpanID, tc.SpanID)
+
+	var buf bytes.Buffer
+	logger.NewContextLogger(log.New(&buf, "", 0)).Info(childCtx, "Working")
+	assert.Equal(t, "INFO: [trace_id="+tc.TraceID.String()+" span_id="+tc.SpanID.String()+"] Working\n", buf.String())
+}
+
+func TestTracerStartContinuesRemoteTrace(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	tracer := NewTracer(exporter)
+
+	remote, err := logger.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	assert.NoError(t, err)
+	remote.State, _ = remote.State.Insert("congo", "t61rcWkgMzE")
+
+	ctx, span := tracer.Start(logger.WithTraceContext(context.Background(), remote), "handler")
+	span.End()
+
+	data := exporter.Spans()[0]
+	assert.Equal(t, remote.TraceID, data.TraceID)
+	assert.Equal(t, remote.SpanID, data.ParentSpanID)
+
+	tc, _ := logger.TraceContextFromContext(ctx)
+	assert.Equal(t, data.SpanID, tc.SpanID)
+	assert.Equal(t, remote.Flags, tc.Flags, "Flags are propagated")
+	assert.Equal(t, remote.State, tc.State, "Trace state is propagated")
+}
+
+func TestDisabledTracer(t *testing.T) {
+	ctx := context.Background()
+	for _, tracer := range []*Tracer{nil, NewTracer(nil)} {
+		assert.False(t, tracer.Enabled())
+		got, span := tracer.Start(ctx, "op")
+		assert.Equal(t, ctx, got)
+		assert.Nil(t, span)
+	}
+	assert.Nil(t, SpanFromContext(nil))
+}
+
+func TestStartUsesDefaultTracer(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	saved := DefaultTracer
+	DefaultTracer = NewTracer(exporter)
+	t.Cleanup(func() { DefaultTracer = saved })
+
+	_, span := Start(context.Background(), "op")
+	span.End()
+	assert.Len(t, exporter.Spans(), 1)
+}
+
+// failingExporter is an Exporter that always fails.
+type failingExporter struct{}
+
+func (failingExporter) ExportSpan(SpanData) error {
+	return errors.New("collector unavailable")
+}
+
+func TestTracerExportError(t *testing.T) {
+	var buf bytes.Buffer
+	tracer := NewTracer(failingExporter{}, WithLogger(logger.NewContextLogger(log.New(&buf, "", 0))))
+
+	_, span := tracer.Start(context.Background(), "op")
+	span.End()
+	assert.Equal(t, "ERROR: Failed to export span op: collector unavailable\n", buf.String())
+}